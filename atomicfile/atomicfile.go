@@ -0,0 +1,42 @@
+// Package atomicfile replaces files so that readers, and a later run after a
+// crash, see either the old content or the complete new content, never a
+// file cut short.
+package atomicfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Write creates or replaces the file at path with perm. The content is
+// written by write into a temporary file in the same directory, which is
+// renamed into place only if write succeeds and removed otherwise. write is
+// given the file itself so it can start over by truncating it.
+func Write(path string, perm os.FileMode, write func(f *os.File) error) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteFile is Write for content already held in memory.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, perm, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,67 @@
+package atomicfile
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tokens.json")
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(path)
+		if err != nil || string(got) != content {
+			t.Errorf("content = %q, %v, want %q", got, err, content)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %s, want 0600", info.Mode().Perm())
+	}
+	if names := dirNames(t, dir); len(names) != 1 {
+		t.Errorf("files left in the directory: %v", names)
+	}
+}
+
+func TestWriteFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.pdf")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("connection reset")
+	err := Write(path, 0644, func(f *os.File) error {
+		f.Write([]byte("part of the n"))
+		return failed
+	})
+	if err != failed {
+		t.Errorf("Write error = %v, want %v", err, failed)
+	}
+	if got, _ := ioutil.ReadFile(path); string(got) != "old" {
+		t.Errorf("content after a failed write = %q, want the old content", got)
+	}
+	if names := dirNames(t, dir); len(names) != 1 {
+		t.Errorf("files left in the directory: %v", names)
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
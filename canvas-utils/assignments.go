@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/fields"
+	"github.com/vericite/canvas-utils/filter"
+	"github.com/vericite/canvas-utils/ltimap"
+	"github.com/vericite/canvas-utils/pool"
+	"github.com/vericite/canvas-utils/report"
+)
+
+var turnitin *bool
+var vericiteLtiMigration *bool
+var columns *string
+var filterExpr *string
+var mixedTypes *bool
+
+// eligibleTypes are the submission types VeriCite can check
+var eligibleTypes = []string{"online_upload", "online_text_entry"}
+
+func init() {
+	register(&command{
+		group:   "assignments",
+		name:    "list",
+		summary: "print the VeriCite-eligible assignments of the listed courses",
+		flags: func() {
+			csvFilename = flag.String("filename", "courses.csv", "a file containing all course ids")
+			turnitin = flag.Bool("turnitin", false, "A flag indicating to only return assignments with TurnItIn enabled")
+			vericiteLtiMigration = flag.Bool("vericiteLtiMigration", false, "A flag indicating to only return VeriCite LTI assignments that need to be migrated")
+			mappingFile = flag.String("mapping", "", "a CSV file of LTI URL mapping rules used by -vericiteLtiMigration (default: the old VeriCite hosts)")
+			workers = flag.Int("workers", 1, "number of courses to fetch concurrently")
+			formatFlag()
+			mixedTypes = flag.Bool("mixedTypes", false, "also return assignments that allow other submission types besides online_upload or online_text_entry, adding eligibleTypes and ineligibleTypes columns")
+			filterExpr = flag.String("filter", "", "an expression selecting assignments by their fields, in place of the submission type check and on top of -vericiteLtiMigration or -mixedTypes, e.g. 'published && \"online_upload\" in submission_types'")
+			columns = flag.String("columns", "assignmentName", "comma-separated assignment fields to print after courseId and assignmentId in CSV output, e.g. name,due_at,published,external_tool_tag_attributes.url")
+		},
+		run: listAssignments,
+	})
+}
+
+func listAssignments() {
+	client := newClient()
+
+	ltiMap, err := ltimap.LoadOrDefault(*mappingFile)
+	if err != nil {
+		panic("Can not load mapping file: " + err.Error())
+	}
+	selection := parseFilter(*filterExpr)
+
+	file, err := os.Open(*csvFilename)
+	if err != nil {
+		panic("Cannot open CSV. Please supply a valid path to a CSV file.")
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+
+	// Collect the course IDs from the input file
+	var courseIDs []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic("Problem reading file")
+		}
+		courseID := record[0]
+		if _, err := strconv.Atoi(courseID); err != nil {
+			//this is most likely the header, skip
+			continue
+		}
+		courseIDs = append(courseIDs, courseID)
+	}
+
+	// Start writing the output
+	columnNames := parseColumns(*columns)
+	header := append([]string{"courseId", "assignmentId"}, columnNames...)
+	if *mixedTypes {
+		header = append(header, "eligibleTypes", "ineligibleTypes")
+	}
+	w := newOutput(header)
+
+	// Get all assignments inside each course, -workers courses at a time
+	pool.Ordered(*workers, len(courseIDs), func(i int) interface{} {
+		start := time.Now()
+		rec := report.Record{Action: "list-assignments", CourseID: courseIDs[i]}
+		var accountID string
+		if *vericiteLtiMigration && ltiMap.NeedsAccount() {
+			course, err := client.WithStatus(&rec.Status).GetCourse(courseIDs[i])
+			if err != nil {
+				runReport.Add(rec, start, err)
+				return courseResult{err: err}
+			}
+			accountID = strconv.Itoa(course.AccountID)
+		}
+		canvasAssignments, err := client.WithStatus(&rec.Status).ListCourseAssignments(courseIDs[i])
+		runReport.Add(rec, start, err)
+		return courseResult{canvasAssignments, accountID, err}
+	}, func(i int, r interface{}) {
+		courseID := courseIDs[i]
+		result := r.(courseResult)
+		if result.err != nil {
+			logger.Warning("Could not fetch assignments for course " + courseID + ". Canvas response: " + result.err.Error())
+		}
+
+		// Loop over each assignment and look for the relevant attribute
+		for _, canvasAssignment := range result.assignments {
+			// -filter narrows down whatever the other flags select
+			if selection != nil && !matchFilter(selection, canvasAssignment) {
+				continue
+			}
+			if *vericiteLtiMigration {
+				//if VeriCite migraiton, then only print assignments whose LTI URL the mapping would rewrite
+				urlToTest := string(canvasAssignment.ExternalToolTagAttributes.URL)
+				if _, ok := ltiMap.Rewrite(urlToTest, courseID, result.accountID); ok {
+					w.Write(assignmentRow(courseID, canvasAssignment, columnNames), canvasAssignment)
+				}
+			} else if *mixedTypes {
+				// any assignment accepting at least one type VeriCite can check
+				eligible, _ := splitSubmissionTypes(canvasAssignment.SubmissionTypes)
+				if len(eligible) > 0 && (*turnitin != true || canvasAssignment.TurnitinEnabled == true) {
+					w.Write(assignmentRow(courseID, canvasAssignment, columnNames), canvasAssignment)
+				}
+			} else if selection != nil {
+				// on its own the filter stands in for the submission type check
+				if *turnitin != true || canvasAssignment.TurnitinEnabled == true {
+					w.Write(assignmentRow(courseID, canvasAssignment, columnNames), canvasAssignment)
+				}
+			} else if ((len(canvasAssignment.SubmissionTypes) == 2 && contains(canvasAssignment.SubmissionTypes, "online_upload") && contains(canvasAssignment.SubmissionTypes, "online_text_entry")) ||
+				(len(canvasAssignment.SubmissionTypes) == 1 && contains(canvasAssignment.SubmissionTypes, "online_upload")) ||
+				(len(canvasAssignment.SubmissionTypes) == 1 && contains(canvasAssignment.SubmissionTypes, "online_text_entry"))) &&
+				(*turnitin != true || canvasAssignment.TurnitinEnabled == true) {
+				w.Write(assignmentRow(courseID, canvasAssignment, columnNames), canvasAssignment)
+			}
+		}
+	})
+	// Flush all output to StdOut
+	w.Close()
+}
+
+// assignmentFields lists every field -columns and -filter can name, including
+// the turnitin_settings Canvas leaves out for some assignments
+var assignmentFields = canvas.Assignment{VeriCiteSettings: &canvas.VeriCiteSettings{}}
+
+// parseFilter compiles the -filter expression and checks the fields it uses;
+// it returns nil when there is no expression
+func parseFilter(expr string) *filter.Filter {
+	if strings.TrimSpace(expr) == "" {
+		return nil
+	}
+	selection, err := filter.Parse(expr)
+	if err != nil {
+		panic("Invalid filter: " + err.Error())
+	}
+	known := map[string]bool{}
+	for _, name := range fields.Names(assignmentFields) {
+		known[name] = true
+	}
+	for _, name := range selection.Fields() {
+		if !known[name] {
+			panic("Unknown field " + name + " in filter; the assignment fields are: " + strings.Join(fields.Names(assignmentFields), ", "))
+		}
+	}
+	return selection
+}
+
+// matchFilter reports whether an assignment is selected by the -filter expression
+func matchFilter(selection *filter.Filter, assignment canvas.Assignment) bool {
+	flat, err := fields.Flatten(assignment)
+	if err != nil {
+		panic("Can not read assignment fields: " + err.Error())
+	}
+	return selection.Match(flat)
+}
+
+// parseColumns checks the -columns list against the assignment fields.
+// assignmentName is the name field, and courseId and assignmentId are left
+// out since they always come first.
+func parseColumns(list string) []string {
+	known := map[string]bool{"assignmentName": true}
+	for _, name := range fields.Names(assignmentFields) {
+		known[name] = true
+	}
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "courseId" || name == "assignmentId" {
+			continue
+		}
+		if !known[name] {
+			panic("Unknown column " + name + "; the assignment fields are: " + strings.Join(fields.Names(assignmentFields), ", "))
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// assignmentRow returns the CSV row of an assignment: courseId, assignmentId,
+// the chosen columns and, with -mixedTypes, its eligible and ineligible
+// submission types
+func assignmentRow(courseID string, assignment canvas.Assignment, columnNames []string) []string {
+	row := []string{courseID, strconv.Itoa(assignment.ID)}
+	flat, err := fields.Flatten(assignment)
+	if err != nil {
+		panic("Can not read assignment fields: " + err.Error())
+	}
+	for _, name := range columnNames {
+		if name == "assignmentName" {
+			name = "name"
+		}
+		row = append(row, fields.Format(flat[name]))
+	}
+	if *mixedTypes {
+		eligible, ineligible := splitSubmissionTypes(assignment.SubmissionTypes)
+		row = append(row, strings.Join(eligible, ","), strings.Join(ineligible, ","))
+	}
+	return row
+}
+
+// splitSubmissionTypes separates the submission types VeriCite can check
+// from the others
+func splitSubmissionTypes(types []string) (eligible []string, ineligible []string) {
+	for _, t := range types {
+		if contains(eligibleTypes, t) {
+			eligible = append(eligible, t)
+		} else {
+			ineligible = append(ineligible, t)
+		}
+	}
+	return eligible, ineligible
+}
+
+// courseResult is what a worker hands back for one course
+type courseResult struct {
+	assignments []canvas.Assignment
+	accountID   string
+	err         error
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
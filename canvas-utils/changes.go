@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/report"
+	"github.com/vericite/canvas-utils/rollback"
+)
+
+// restoreRollback puts back the settings recorded in a rollback file, giving
+// each assignment the values it had before the first recorded change
+func restoreRollback(client *canvas.Client, path string) {
+	entries, err := rollback.Read(path)
+	if entries == nil && err != nil {
+		panic("Can not read rollback file: " + err.Error())
+	} else if err != nil {
+		logger.Warning(err.Error())
+	}
+
+	for _, entry := range rollback.Originals(entries) {
+		data := entry.Values()
+		if len(data) == 0 {
+			// nothing was recorded for this assignment
+			continue
+		}
+		start := time.Now()
+		rec := report.Record{Action: "restore", CourseID: entry.CourseID, AssignmentID: entry.AssignmentID}
+		if *dryRun {
+			rec.Outcome = report.DryRun
+			runReport.Add(rec, start, printDiff(client.WithStatus(&rec.Status), entry.CourseID, entry.AssignmentID, data))
+			continue
+		}
+		_, err := client.WithStatus(&rec.Status).UpdateAssignment(entry.CourseID, entry.AssignmentID, data)
+		runReport.Add(rec, start, err)
+		if err == nil {
+			logger.Info("Restored assignment: " + entry.CourseID + ":" + entry.AssignmentID)
+		} else {
+			logger.Warning("Could not restore assignment: " + entry.CourseID + ":" + entry.AssignmentID + "; " + err.Error())
+			if apiErr, ok := err.(*canvas.Error); ok {
+				logger.Warning("Response body: " + apiErr.Body)
+			}
+		}
+	}
+}
+
+// printDiff fetches an assignment and prints the changes data would make to
+// it. It returns the error fetching the assignment, if any.
+func printDiff(client *canvas.Client, courseID string, assignmentID string, data url.Values) error {
+	assignment, err := client.GetAssignment(courseID, assignmentID)
+	if err != nil {
+		logger.Warning("Could not fetch assignment: " + courseID + ":" + assignmentID + "; " + err.Error())
+		return err
+	}
+	printChanges(courseID, assignmentID, assignment, data)
+	return nil
+}
+
+// printChanges prints the changes data would make to an assignment
+func printChanges(courseID string, assignmentID string, assignment *canvas.Assignment, data url.Values) {
+	changes := assignment.Diff(data)
+	fmt.Println("course " + courseID + " assignment " + assignmentID + " (" + assignment.Name + "): " + strconv.Itoa(len(changes)) + " change(s)")
+	for _, change := range changes {
+		fmt.Println("  " + change.Param + ": " + change.Old + " -> " + change.New)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/report"
+)
+
+var accountId *string
+var termId *string
+var withEnrollments *string
+var published *string
+var completed *string
+var blueprint *string
+var searchTerm *string
+var states *string
+var bySubaccounts *string
+var accountColumn *bool
+
+func init() {
+	register(&command{
+		group:   "courses",
+		name:    "list",
+		summary: "print the courses of an account and term",
+		flags: func() {
+			accountId = flag.String("accountId", "1", "account id to look up courses")
+			termId = flag.String("termId", "1", "term id for requested account courses")
+			withEnrollments = flag.String("withEnrollments", "", "true for only courses with enrollments, false for only courses without")
+			published = flag.String("published", "", "true for only published courses, false for only unpublished ones")
+			completed = flag.String("completed", "", "true for only completed courses, false for only courses not completed")
+			blueprint = flag.String("blueprint", "", "true for only blueprint courses, false for only non-blueprint ones")
+			searchTerm = flag.String("searchTerm", "", "only courses whose name, code or SIS id contains this (at least 3 characters)")
+			states = flag.String("state", "", "comma-separated workflow states to include: created, claimed, available, completed, deleted, all")
+			bySubaccounts = flag.String("bySubaccounts", "", "comma-separated sub-account ids to restrict the courses to")
+			accountColumn = flag.Bool("accountColumn", false, "add an accountId column with the account each course belongs to; courses of sub-accounts are always listed")
+			formatFlag()
+		},
+		run: listCourses,
+	})
+}
+
+func listCourses() {
+	client := newClient()
+	filter := courseFilter()
+
+	header := []string{"courseID", "courseName"}
+	if *accountColumn {
+		header = append(header, "accountId")
+	}
+	w := newOutput(header)
+
+	// Canvas lists the courses of every sub-account along with the account's
+	// own, so a single query covers the whole tree
+	start := time.Now()
+	rec := report.Record{Action: "list-courses", AccountID: *accountId}
+	canvasCourses, err := client.WithStatus(&rec.Status).ListAccountCourses(*accountId, filter)
+	runReport.Add(rec, start, err)
+	if err != nil {
+		logger.Warning("Could not fetch courses for account " + *accountId + " and term: " + *termId + ". Canvas response: " + err.Error())
+	}
+	for _, canvasCourse := range canvasCourses {
+		row := []string{strconv.Itoa(canvasCourse.ID), canvasCourse.Name}
+		if *accountColumn {
+			row = append(row, strconv.Itoa(canvasCourse.AccountID))
+		}
+		w.Write(row, canvasCourse)
+	}
+	// Flush all output to StdOut
+	w.Close()
+}
+
+// courseFilter builds the Canvas course filters from the flags
+func courseFilter() canvas.CourseFilter {
+	for name, value := range map[string]string{"withEnrollments": *withEnrollments, "published": *published, "completed": *completed, "blueprint": *blueprint} {
+		if value != "" && value != "true" && value != "false" {
+			panic(name + " can only be true or false")
+		}
+	}
+	if *searchTerm != "" && len(*searchTerm) < 3 {
+		panic("searchTerm must be at least 3 characters")
+	}
+	return canvas.CourseFilter{
+		TermID:          *termId,
+		WithEnrollments: *withEnrollments,
+		Published:       *published,
+		Completed:       *completed,
+		Blueprint:       *blueprint,
+		SearchTerm:      *searchTerm,
+		State:           splitList(*states),
+		BySubaccounts:   splitList(*bySubaccounts),
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
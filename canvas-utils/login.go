@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/keyring"
+	"github.com/vericite/canvas-utils/oauth"
+	"golang.org/x/term"
+)
+
+func init() {
+	register(&command{
+		group:   "login",
+		summary: "check a Canvas token and save it in the encrypted keyring",
+		run:     login,
+	})
+}
+
+func login() {
+	baseURL, token, _ := credentials()
+	root, err := oauth.Root(baseURL)
+	if err != nil {
+		panic("Invalid Canvas URL: " + err.Error())
+	}
+	if token == "" {
+		token = readSecret("Canvas token for " + root + ": ")
+	}
+	if token == "" {
+		panic("No token given")
+	}
+
+	client := canvas.NewClient(baseURL, token)
+	client.Debugf = logger.Debugf
+	client.Retry = canvas.NewRetryPolicy(*retries)
+	user, err := client.GetSelf()
+	if err != nil {
+		panic("Canvas rejected the token: " + err.Error())
+	}
+
+	exists := keyring.Exists(*keyringFile)
+	pass := passphrase("Keyring passphrase: ", !exists)
+	ring, err := keyring.Open(*keyringFile, pass)
+	if err != nil {
+		panic("Can not open keyring: " + err.Error())
+	}
+	ring.Set(root, token)
+	if err := ring.Save(); err != nil {
+		panic("Can not save keyring: " + err.Error())
+	}
+	logger.Info("Saved the token of " + user.Name + " for " + root + " in " + *keyringFile)
+}
+
+// passphrase returns the keyring passphrase from CANVAS_KEYRING_PASSPHRASE or
+// asks for it on the terminal, twice when a new keyring is created
+func passphrase(label string, confirm bool) string {
+	if pass := os.Getenv(keyring.EnvPassphrase); pass != "" {
+		return pass
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		panic("No keyring passphrase: set " + keyring.EnvPassphrase + " for unattended runs")
+	}
+	pass := readSecret(label)
+	if pass == "" {
+		panic("No keyring passphrase given")
+	}
+	if confirm && readSecret("Repeat passphrase: ") != pass {
+		panic("Passphrases do not match")
+	}
+	return pass
+}
+
+// readSecret asks for a line on the terminal without echoing it. It fails
+// when stdin is not a terminal so secrets are never read from a pipe by
+// accident.
+func readSecret(label string) string {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		panic("Can not ask for a secret: standard input is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, label)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		panic("Can not read from the terminal: " + err.Error())
+	}
+	return strings.TrimSpace(string(b))
+}
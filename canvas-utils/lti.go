@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/ltimap"
+	"github.com/vericite/canvas-utils/report"
+	"github.com/vericite/canvas-utils/rollback"
+)
+
+var reverse *bool
+
+// courseAccounts caches the account id of each course looked up for the mapping
+var courseAccounts = map[string]string{}
+
+func init() {
+	register(&command{
+		group:   "lti",
+		name:    "rewrite",
+		summary: "move the listed assignments to a new LTI launch URL",
+		flags: func() {
+			csvFilename = flag.String("filename", "assignments.csv", "a file containing all assignment ids")
+			dryRun = flag.Bool("dry-run", false, "print what would change for each assignment without modifying it")
+			rollbackFile = flag.String("rollback", "rewrite-assignment-urls.rollback", "a file recording each assignment's URL before it is rewritten")
+			reverse = flag.Bool("reverse", false, "restore the URLs recorded in the rollback file instead of rewriting them")
+			mappingFile = flag.String("mapping", "", "a CSV file of LTI URL mapping rules (default: the old VeriCite hosts to "+ltimap.VeriCiteURL+")")
+		},
+		run: rewriteURLs,
+	})
+}
+
+func rewriteURLs() {
+	client := newClient()
+
+	if *reverse {
+		// An assignment rewritten by several runs gets the URL it had before the first of them
+		restoreRollback(client, *rollbackFile)
+		return
+	}
+
+	ltiMap, err := ltimap.LoadOrDefault(*mappingFile)
+	if err != nil {
+		panic("Can not load mapping file: " + err.Error())
+	}
+
+	file, err := os.Open(*csvFilename)
+	if err != nil {
+		panic("Can not open CSV")
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+
+	// A dry run changes nothing, so it leaves the rollback log alone
+	var rollbackLog *rollback.Log
+	if !*dryRun {
+		rollbackLog, err = rollback.Open(*rollbackFile)
+		if err != nil {
+			panic("Can not open rollback file: " + err.Error())
+		}
+		defer rollbackLog.Close()
+	}
+
+	// Loop through the file containing course IDs
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic("Problem reading file")
+		}
+		if len(record) < 2 {
+			continue
+		}
+		courseID := record[0]
+		assignmentID := record[1]
+		if _, errconv := strconv.Atoi(courseID); errconv != nil {
+			//this is most likely the header, skip
+			continue
+		}
+
+		// Look up the current URL and where the mapping sends it
+		start := time.Now()
+		rec := report.Record{Action: "rewrite-url", CourseID: courseID, AssignmentID: assignmentID}
+		assignment, err := client.WithStatus(&rec.Status).GetAssignment(courseID, assignmentID)
+		if err != nil {
+			logger.Warning("Could not fetch assignment: " + courseID + ":" + assignmentID + "; " + err.Error())
+			rec.Action = "get-assignment"
+			runReport.Add(rec, start, err)
+			continue
+		}
+		// Only the first two columns are read, so the input may come from
+		// assignments list with any -columns
+		assignmentName := assignment.Name
+		var accountID string
+		if ltiMap.NeedsAccount() {
+			if accountID, err = courseAccount(client.WithStatus(&rec.Status), courseID); err != nil {
+				logger.Warning("Could not fetch course: " + courseID + "; " + err.Error())
+				rec.Action = "get-course"
+				runReport.Add(rec, start, err)
+				continue
+			}
+		}
+		newURL, ok := ltiMap.Rewrite(assignment.ExternalToolTagAttributes.URL, courseID, accountID)
+		if !ok {
+			logger.Info("No mapping for assignment: " + courseID + ":" + assignmentID + ":" + assignmentName + " URL: " + assignment.ExternalToolTagAttributes.URL)
+			rec.Outcome = report.Skipped
+			runReport.Add(rec, start, nil)
+			continue
+		}
+
+		data := url.Values{}
+		data.Set("assignment[external_tool_tag_attributes][url]", newURL)
+		if *dryRun {
+			printChanges(courseID, assignmentID, assignment, data)
+			rec.Outcome = report.DryRun
+			runReport.Add(rec, start, nil)
+			continue
+		}
+		// Record the old URL so the rewrite can be reversed
+		err = rollbackLog.Record(courseID, assignmentID, assignment.Snapshot(data))
+		if err != nil {
+			logger.Warning("Could not record old URL of assignment: " + courseID + ":" + assignmentID + ":" + assignmentName + ", leaving it unchanged; " + err.Error())
+			rec.Action = "snapshot"
+			runReport.Add(rec, start, err)
+			continue
+		}
+
+		// Modify this one assignment field
+		_, err = client.WithStatus(&rec.Status).UpdateAssignment(courseID, assignmentID, data)
+		runReport.Add(rec, start, err)
+		if err == nil {
+			logger.Info("Modified assignment: " + courseID + ":" + assignmentID + ":" + assignmentName)
+		} else if apiErr, ok := err.(*canvas.Error); ok {
+			logger.Warning("Could not modify assignment: " + courseID + ":" + assignmentID + ":" + assignmentName + "; " + err.Error())
+			logger.Warning("Response body: " + apiErr.Body)
+		} else {
+			logger.Warning("Could not modify assignment: " + courseID + ":" + assignmentID + ":" + assignmentName + "; " + err.Error())
+		}
+	}
+}
+
+// courseAccount returns the account id of a course, fetching each course once
+func courseAccount(client *canvas.Client, courseID string) (string, error) {
+	if accountID, ok := courseAccounts[courseID]; ok {
+		return accountID, nil
+	}
+	course, err := client.GetCourse(courseID)
+	if err != nil {
+		return "", err
+	}
+	courseAccounts[courseID] = strconv.Itoa(course.AccountID)
+	return courseAccounts[courseID], nil
+}
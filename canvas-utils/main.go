@@ -0,0 +1,238 @@
+// Command canvas-utils bundles the Canvas maintenance scripts into a single
+// binary with subcommands:
+//
+//	canvas-utils [global flags] <group> <command> [flags]
+//
+// Run "canvas-utils help" for the list of commands and
+// "canvas-utils <group> <command> -h" for the flags of one command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alexcesaro/log"
+	"github.com/alexcesaro/log/stdlog"
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/config"
+	"github.com/vericite/canvas-utils/keyring"
+	"github.com/vericite/canvas-utils/oauth"
+	"github.com/vericite/canvas-utils/report"
+)
+
+// Set at build time with -ldflags "-X main.Version=... -X main.BuildDate=..."
+var Version = "dev"
+var BuildDate = ""
+
+// Global flags shared by every command. The Canvas URL and token can also come
+// from the CANVAS_URL and CANVAS_TOKEN environment variables or a profile in
+// the configuration file, in that order of precedence after the flags.
+var canvasBase = flag.String("url", "", "the base URL for the Canvas API (or "+config.EnvURL+")")
+var canvasAuth = flag.String("token", "", "the Canvas authentication token after the word Bearer (or "+config.EnvToken+")")
+var configFile = flag.String("config", config.DefaultPath(), "the configuration file holding connection profiles")
+var profileName = flag.String("profile", os.Getenv(config.EnvProfile), "the configuration profile to use (default: the file's default profile)")
+var retries = flag.Int("retries", canvas.DefaultRetries, "how many times to retry a Canvas request that failed for a transient reason")
+var keyringFile = flag.String("keyring", keyring.DefaultPath(), "the encrypted file holding tokens saved by \"login\" (or "+keyring.EnvPath+")")
+var reportFile = flag.String("report", "", "write a JSON Lines report with one record per processed entity to this file")
+var oauthStore = flag.String("oauthTokens", oauth.DefaultStorePath(), "the file holding OAuth2 tokens saved by \"oauth authorize\" (or "+oauth.EnvStore+")")
+
+// globalValueFlags are the global flags that take a value, so "-url x" can be
+// told apart from the command words when flags come before the command
+var globalValueFlags = map[string]bool{"url": true, "token": true, "config": true, "profile": true, "retries": true, "keyring": true, "report": true, "oauthTokens": true, "log": true}
+
+// Flags used by several commands; each command registers the ones it uses
+// with its own default and description
+var csvFilename *string
+var dryRun *bool
+var workers *int
+var journalFile *string
+var resume *bool
+var rollbackFile *string
+var mappingFile *string
+
+// runReport receives one record per entity processed when -report is given;
+// it is nil, and discards records, otherwise
+var runReport *report.Writer
+
+// Use -log=debug to get debug-level output; set up in main once the command
+// has registered its flags
+var logger log.Logger
+
+// command is one "<group> <name>" subcommand, or a single-word command such
+// as "login" when name is empty
+type command struct {
+	group string
+	name  string
+	// summary completes the sentence "The <group> <name> command will ..."
+	summary string
+	// flags registers the command's flags on flag.CommandLine
+	flags func()
+	run   func()
+}
+
+var commands = map[string]*command{}
+
+func register(cmd *command) {
+	commands[cmd.String()] = cmd
+}
+
+// String returns the words that invoke the command
+func (cmd *command) String() string {
+	return strings.TrimSpace(cmd.group + " " + cmd.name)
+}
+
+func main() {
+	words, flagArgs := splitArgs(os.Args[1:])
+	if len(words) == 1 && words[0] == "version" {
+		fmt.Println("canvas-utils " + Version + " " + BuildDate)
+		return
+	}
+	cmd := commands[strings.Join(words, " ")]
+	if cmd == nil {
+		usage()
+		if len(words) == 0 || words[0] == "help" {
+			return
+		}
+		os.Exit(2)
+	}
+	if cmd.flags != nil {
+		cmd.flags()
+	}
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: canvas-utils %s [flags]\n\nThe %s command will %s.\n\nFlags:\n", cmd, cmd, cmd.summary)
+		flag.PrintDefaults()
+	}
+	// stdlog parses flag.CommandLine from os.Args, so hand it only the flags
+	os.Args = append([]string{os.Args[0]}, flagArgs...)
+	logger = stdlog.GetFromFlags()
+	if flag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "unexpected arguments: "+strings.Join(flag.Args(), " "))
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *reportFile != "" {
+		var err error
+		if runReport, err = report.Open(*reportFile, cmd.String()); err != nil {
+			panic("Can not open report file: " + err.Error())
+		}
+		defer runReport.Close()
+	}
+	cmd.run()
+}
+
+// splitArgs separates the command words from the flags, which may come
+// before or after them.
+func splitArgs(args []string) (words []string, flags []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case len(words) == 2:
+			return words, append(flags, args[i:]...)
+		case !strings.HasPrefix(arg, "-"):
+			words = append(words, arg)
+		default:
+			flags = append(flags, arg)
+			name := strings.TrimLeft(arg, "-")
+			if !strings.Contains(name, "=") && globalValueFlags[name] && i+1 < len(args) {
+				i++
+				flags = append(flags, args[i])
+			}
+		}
+	}
+	return words, flags
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: canvas-utils [global flags] <group> <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nGlobal flags:")
+	fmt.Fprintln(os.Stderr, "  -url string      the base URL for the Canvas API (or "+config.EnvURL+")")
+	fmt.Fprintln(os.Stderr, "  -token string    the Canvas authentication token after the word Bearer (or "+config.EnvToken+")")
+	fmt.Fprintln(os.Stderr, "  -config string   the configuration file holding connection profiles (default "+config.DefaultPath()+")")
+	fmt.Fprintln(os.Stderr, "  -profile string  the configuration profile to use")
+	fmt.Fprintln(os.Stderr, "  -retries int     how many times to retry a Canvas request that failed for a transient reason")
+	fmt.Fprintln(os.Stderr, "  -report string   write a JSON Lines report with one record per processed entity")
+	fmt.Fprintln(os.Stderr, "  -keyring string  the encrypted file holding tokens saved by \"login\" (default "+keyring.DefaultPath()+")")
+	fmt.Fprintln(os.Stderr, "  -oauthTokens string  the file holding OAuth2 tokens (default "+oauth.DefaultStorePath()+")")
+	fmt.Fprintln(os.Stderr, "  -log string      sets the logging threshold (default \"info\")")
+	fmt.Fprintln(os.Stderr, "\nRun \"canvas-utils <group> <command> -h\" for the flags of a command.")
+}
+
+// newClient returns a Canvas client configured from the global flags,
+// environment and configuration file. Without a token it falls back to the
+// OAuth2 tokens saved by "oauth authorize" for the Canvas instance, which are
+// refreshed as they expire, then to the token saved by "login".
+func newClient() *canvas.Client {
+	baseURL, token, _ := credentials()
+	client := canvas.NewClient(baseURL, token)
+	client.Debugf = logger.Debugf
+	client.Retry = canvas.NewRetryPolicy(*retries)
+	if token != "" {
+		return client
+	}
+	root, err := oauth.Root(baseURL)
+	if err != nil {
+		panic("Invalid Canvas URL: " + err.Error())
+	}
+	store := oauth.NewStore(*oauthStore)
+	creds, err := store.Get(root)
+	if err != nil {
+		panic("Can not read OAuth2 tokens: " + err.Error())
+	}
+	if creds != nil {
+		client.TokenSource = oauth.NewTokenSource(root, creds, func(c *oauth.Credentials) error {
+			logger.Debug("Refreshed the OAuth2 access token for " + root)
+			return store.Put(root, c)
+		})
+		return client
+	}
+	if keyring.Exists(*keyringFile) {
+		ring, err := keyring.Open(*keyringFile, passphrase("Keyring passphrase: ", false))
+		if err != nil {
+			panic("Can not open keyring: " + err.Error())
+		}
+		client.Token = ring.Get(root)
+	}
+	if client.Token == "" {
+		panic("No Canvas token for " + root + ": use -token, " + config.EnvToken + ", a configuration profile, \"canvas-utils login\" or \"canvas-utils oauth authorize\"")
+	}
+	return client
+}
+
+// credentials resolves the Canvas URL and token with the precedence
+// flag > environment variable > configuration profile, and returns the
+// selected profile for its other settings. The token may be empty.
+func credentials() (string, string, config.Profile) {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	file, err := config.Load(*configFile)
+	if err != nil {
+		panic("Can not read configuration file: " + err.Error())
+	}
+	profile, err := file.Profile(*profileName)
+	if err != nil {
+		panic("Can not use configuration profile: " + err.Error())
+	}
+	if (profile.Token != "" || profile.ClientSecret != "") && config.Exposed(*configFile) {
+		logger.Warning("Configuration file " + *configFile + " holds secrets but can be read by other users; chmod 600 it")
+	}
+	baseURL := config.Resolve(*canvasBase, set["url"], config.EnvURL, profile.URL)
+	token := config.Resolve(*canvasAuth, set["token"], config.EnvToken, profile.Token)
+	if baseURL == "" {
+		panic("No Canvas URL: use -url, " + config.EnvURL + " or a configuration profile")
+	}
+	return baseURL, token, profile
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vericite/canvas-utils/oauth"
+)
+
+var clientId *string
+var clientSecret *string
+var callbackPort *int
+
+func init() {
+	register(&command{
+		group:   "oauth",
+		name:    "authorize",
+		summary: "authorize canvas-utils with a Canvas developer key and save its tokens",
+		flags: func() {
+			clientId = flag.String("clientId", "", "the developer key's client id (default: the profile's client_id)")
+			clientSecret = flag.String("clientSecret", "", "the developer key's client secret (default: the profile's client_secret)")
+			callbackPort = flag.Int("port", oauth.DefaultPort, "the local port Canvas redirects to; the developer key must allow http://localhost:<port>"+oauth.CallbackPath)
+		},
+		run: authorizeOAuth,
+	})
+}
+
+func authorizeOAuth() {
+	baseURL, _, profile := credentials()
+	root, err := oauth.Root(baseURL)
+	if err != nil {
+		panic("Invalid Canvas URL: " + err.Error())
+	}
+	id, secret := *clientId, *clientSecret
+	if id == "" {
+		id = profile.ClientID
+	}
+	if secret == "" {
+		secret = profile.ClientSecret
+	}
+	if id == "" || secret == "" {
+		panic("No developer key: use -clientId and -clientSecret or client_id and client_secret in the configuration profile")
+	}
+
+	creds, err := oauth.Authorize(root, id, secret, *callbackPort, 5*time.Minute, func(authURL string) {
+		fmt.Fprintln(os.Stderr, "Open this URL in a browser and authorize canvas-utils:")
+		fmt.Fprintln(os.Stderr, "\n  "+authURL+"\n")
+	})
+	if err != nil {
+		panic("Authorization failed: " + err.Error())
+	}
+	if creds.RefreshToken == "" {
+		logger.Warning("Canvas issued no refresh token; the access token can not be renewed once it expires")
+	}
+	if err := oauth.NewStore(*oauthStore).Put(root, creds); err != nil {
+		panic("Can not save OAuth2 tokens: " + err.Error())
+	}
+	logger.Info("Saved OAuth2 tokens for " + root + " in " + *oauthStore)
+}
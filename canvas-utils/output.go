@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+)
+
+// Output formats of the listing commands
+const (
+	formatCSV   = "csv"
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
+)
+
+var format *string
+
+// formatFlag registers -format for a listing command
+func formatFlag() {
+	format = flag.String("format", formatCSV, "output format: csv for the id columns, json for an array of full objects or jsonl for one full object per line")
+}
+
+// output writes the results of a listing command to stdout: the given
+// columns as CSV, or the full Canvas objects as a JSON array or JSON Lines
+type output struct {
+	format string
+	w      io.Writer
+	csv    *csv.Writer
+	n      int
+}
+
+// newOutput starts the output in -format; header is used for CSV only
+func newOutput(header []string) *output {
+	o := &output{format: *format, w: os.Stdout}
+	switch o.format {
+	case formatCSV:
+		o.csv = csv.NewWriter(o.w)
+		o.csv.Write(header)
+	case formatJSON, formatJSONL:
+	default:
+		panic("Unknown format " + o.format + ": use csv, json or jsonl")
+	}
+	return o
+}
+
+// Write outputs one result: row as CSV, v as JSON
+func (o *output) Write(row []string, v interface{}) {
+	if o.csv != nil {
+		o.csv.Write(row)
+		return
+	}
+	var data []byte
+	var err error
+	if o.format == formatJSON {
+		data, err = json.MarshalIndent(v, "  ", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		panic("Can not encode result: " + err.Error())
+	}
+	if o.format == formatJSON {
+		if o.n == 0 {
+			io.WriteString(o.w, "[\n  ")
+		} else {
+			io.WriteString(o.w, ",\n  ")
+		}
+	}
+	o.w.Write(data)
+	if o.format == formatJSONL {
+		io.WriteString(o.w, "\n")
+	}
+	o.n++
+}
+
+// Close ends the output, flushing CSV and closing the JSON array
+func (o *output) Close() {
+	switch {
+	case o.csv != nil:
+		o.csv.Flush()
+	case o.format == formatJSON && o.n == 0:
+		io.WriteString(o.w, "[]\n")
+	case o.format == formatJSON:
+		io.WriteString(o.w, "\n]\n")
+	}
+}
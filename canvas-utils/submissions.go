@@ -0,0 +1,408 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vericite/canvas-utils/atomicfile"
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/htmltext"
+	"github.com/vericite/canvas-utils/journal"
+	"github.com/vericite/canvas-utils/manifest"
+	"github.com/vericite/canvas-utils/pool"
+	"github.com/vericite/canvas-utils/report"
+	"github.com/vericite/canvas-utils/safepath"
+)
+
+var outputFolder *string
+var allAttempts *bool
+var incremental *bool
+var since *string
+
+// sinceTime is -since parsed; zero when not given
+var sinceTime time.Time
+
+// exportManifest indexes the files written to -outputFolder
+var exportManifest *manifest.Manifest
+
+func init() {
+	register(&command{
+		group:   "submissions",
+		name:    "export",
+		summary: "download the submissions of the listed assignments",
+		flags: func() {
+			csvFilename = flag.String("filename", "assignments.csv", "a file containing all assignment ids")
+			outputFolder = flag.String("outputFolder", "submissions", "a path for where the submissions will be stored")
+			allAttempts = flag.Bool("allAttempts", false, "export every attempt of each submission into attempt-<n> directories, not just the latest")
+			incremental = flag.Bool("incremental", false, "skip files already exported, per the manifest, that have not changed in Canvas since")
+			since = flag.String("since", "", "only export submissions submitted after this time, e.g. 2026-09-01 or 2026-09-01T12:00:00Z")
+			workers = flag.Int("workers", 1, "number of assignments to export concurrently")
+			journalFile = flag.String("journal", "export-submissions.journal", "a file recording the outcome of each assignment")
+			resume = flag.Bool("resume", false, "skip assignments the journal records as done and retry the rest")
+		},
+		run: exportSubmissions,
+	})
+}
+
+func exportSubmissions() {
+	client := newClient()
+
+	if *since != "" {
+		var err error
+		if sinceTime, err = parseTime(*since); err != nil {
+			panic("Invalid -since: use a date like 2026-09-01 or a time like 2026-09-01T12:00:00Z")
+		}
+	}
+
+	file, err := os.Open(*csvFilename)
+	if err != nil {
+		panic("Cannot open CSV. Please supply a valid path to a CSV file.")
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+
+	runJournal, err := journal.Open(*journalFile, *resume)
+	if err != nil {
+		panic("Can not open journal: " + err.Error())
+	}
+	defer runJournal.Close()
+
+	exportManifest, err = manifest.Open(*outputFolder)
+	if err != nil {
+		panic("Can not open manifest: " + err.Error())
+	}
+	defer exportManifest.Close()
+
+	// Collect the course and assignment IDs from the input file
+	var rows [][2]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic("Problem reading file")
+		}
+		courseID := record[0]
+		assignmentID := record[1]
+		if _, err := strconv.Atoi(courseID); err != nil {
+			//this is most likely the header, skip
+			continue
+		}
+		if *resume && runJournal.Done(courseID, assignmentID) {
+			logger.Debug("Skipping assignment already exported: " + assignmentID)
+			runReport.Add(report.Record{Action: "list-submissions", CourseID: courseID, AssignmentID: assignmentID, Outcome: report.Skipped}, time.Now(), nil)
+			continue
+		}
+		rows = append(rows, [2]string{courseID, assignmentID})
+	}
+
+	// Export each assignment, -workers assignments at a time
+	pool.Ordered(*workers, len(rows), func(i int) interface{} {
+		courseID, assignmentID := rows[i][0], rows[i][1]
+		if err := exportAssignment(client, courseID, assignmentID); err != nil {
+			runJournal.Record(courseID, assignmentID, journal.Failed, err.Error())
+		} else {
+			runJournal.Record(courseID, assignmentID, journal.Done, "")
+		}
+		return nil
+	}, func(i int, r interface{}) {})
+}
+
+// exportAssignment downloads the attachments of every submission to one
+// assignment and writes out the text of text entry submissions, and with
+// -allAttempts does so for every attempt. It returns the first error
+// encountered, if any.
+func exportAssignment(client *canvas.Client, courseID, assignmentID string) error {
+	// Get all submissions for this assignment
+	logger.Debug("Fetching submissions for course " + courseID + " assignment: " + assignmentID)
+	var include []string
+	if *allAttempts {
+		include = append(include, "submission_history")
+	}
+	start := time.Now()
+	rec := report.Record{Action: "list-submissions", CourseID: courseID, AssignmentID: assignmentID}
+	canvasSubmissions, firstErr := client.WithStatus(&rec.Status).ListSubmissions(courseID, assignmentID, include...)
+	runReport.Add(rec, start, firstErr)
+	if firstErr != nil {
+		logger.Warning("Could not fetch submissions for course " + courseID + " assignment: " + assignmentID + ". Canvas response: " + firstErr.Error())
+	}
+
+	// The ids come from the input file, so they are made safe like file names
+	dir, err := safepath.Join(*outputFolder, courseID, assignmentID)
+	if err != nil {
+		return err
+	}
+	for _, canvasSubmission := range canvasSubmissions {
+		if !*allAttempts || len(canvasSubmission.SubmissionHistory) == 0 {
+			if err := exportSubmission(client, courseID, assignmentID, dir, canvasSubmission, 0); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		// Each attempt goes to a directory of its own
+		for _, attempt := range canvasSubmission.SubmissionHistory {
+			if attempt.Attempt == 0 {
+				// a version recorded before anything was submitted, e.g. a grade
+				continue
+			}
+			if attempt.UserID == 0 {
+				attempt.UserID = canvasSubmission.UserID
+			}
+			err := exportSubmission(client, courseID, assignmentID, filepath.Join(dir, "attempt-"+strconv.Itoa(attempt.Attempt)), attempt, attempt.Attempt)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// exportSubmission downloads the attachments or writes the text of one
+// submission, or one attempt of it, into dir
+func exportSubmission(client *canvas.Client, courseID, assignmentID, dir string, submission canvas.Submission, attempt int) error {
+	if !sinceTime.IsZero() && !submittedSince(submission, sinceTime) {
+		logger.Debug("Skipping submission of user " + strconv.Itoa(submission.UserID) + " to assignment " + assignmentID + " submitted at " + submission.SubmittedAt)
+		return nil
+	}
+	var firstErr error
+	rec := report.Record{
+		CourseID:     courseID,
+		AssignmentID: assignmentID,
+		UserID:       strconv.Itoa(submission.UserID),
+		Attempt:      attempt,
+	}
+	entry := manifest.Entry{
+		CourseID:     courseID,
+		AssignmentID: assignmentID,
+		UserID:       strconv.Itoa(submission.UserID),
+		Attempt:      strconv.Itoa(submission.Attempt),
+	}
+	if submission.SubmissionType == "online_text_entry" && len(submission.Body) > 0 {
+		start := time.Now()
+		entry.UpdatedAt = submission.SubmittedAt
+		written, err := writeTextEntry(dir, submission, entry)
+		rec.Action = "write-text"
+		rec.Outcome = ""
+		if !written {
+			rec.Outcome = report.Skipped
+		}
+		runReport.Add(rec, start, err)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if submission.SubmissionType == "online_upload" && len(submission.Attachments) > 0 {
+		for _, attachment := range submission.Attachments {
+			if len(attachment.URL) > 0 {
+				start := time.Now()
+				// The file name is chosen by the student, so it is made safe to
+				// write; the manifest keeps the original
+				fileName := safepath.Name(strconv.Itoa(attachment.ID) + attachment.FileName)
+				rec.Action = "download"
+				rec.FileID = strconv.Itoa(attachment.ID)
+				rec.Outcome = ""
+				rec.Status = 0
+				if *incremental && unchanged(filepath.Join(dir, fileName), attachment.UpdatedAt, attachment.Size) {
+					logger.Debug("Skipping unchanged " + filepath.Join(dir, fileName))
+					rec.Outcome = report.Skipped
+					runReport.Add(rec, start, nil)
+					continue
+				}
+				size, contentType, sum, err := downloadFromUrl(client.WithStatus(&rec.Status), attachment, dir, fileName)
+				if err == nil {
+					entry.FileID = strconv.Itoa(attachment.ID)
+					entry.OriginalName = attachment.FileName
+					entry.Path = exportManifest.Rel(filepath.Join(dir, fileName))
+					entry.Size, entry.ContentType, entry.SHA256 = size, contentType, sum
+					entry.UpdatedAt = attachment.UpdatedAt
+					err = exportManifest.Add(entry)
+				}
+				runReport.Add(rec, start, err)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	return firstErr
+}
+
+// writeTextEntry saves the body of a text entry submission twice, as an HTML
+// page and as plain text with the markup removed, and records both files in
+// the manifest. With -incremental files that have not changed are left
+// alone; it reports whether anything was written.
+func writeTextEntry(filePath string, submission canvas.Submission, entry manifest.Entry) (bool, error) {
+	name := filepath.Join(filePath, strconv.Itoa(submission.UserID)+"-text")
+	page := "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"></head>\n<body>\n" + submission.Body + "\n</body>\n</html>\n"
+	files := [][3]string{
+		{".html", "text/html; charset=utf-8", page},
+		{".txt", "text/plain; charset=utf-8", htmltext.Text(submission.Body)},
+	}
+	written := false
+	for _, file := range files {
+		ext, contentType, content := file[0], file[1], file[2]
+		if *incremental && unchanged(name+ext, submission.SubmittedAt, 0) {
+			logger.Debug("Skipping unchanged " + name + ext)
+			continue
+		}
+		written = true
+		fmt.Println(name + ext)
+		size, sum, err := writeAtomic(name+ext, func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		})
+		if err != nil {
+			fmt.Println("Error while writing", name+ext, "-", err)
+			return written, err
+		}
+		entry.Path = exportManifest.Rel(name + ext)
+		entry.Size, entry.ContentType, entry.SHA256 = size, contentType, sum
+		if err := exportManifest.Add(entry); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// unchanged reports whether the manifest records path as exported from the
+// version of the file Canvas lists now (updatedAt, and size when known) and
+// the file on disk still has the recorded size and checksum
+func unchanged(path string, updatedAt string, size int64) bool {
+	entry, ok := exportManifest.Get(exportManifest.Rel(path))
+	if !ok || entry.UpdatedAt != updatedAt || (size > 0 && entry.Size != size) {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != entry.Size {
+		return false
+	}
+	if entry.SHA256 == "" {
+		return true
+	}
+	sum, err := fileSHA256(path)
+	return err == nil && sum == entry.SHA256
+}
+
+// fileSHA256 returns the hex SHA-256 checksum of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// submittedSince reports whether a submission was submitted after t;
+// submissions never submitted are not
+func submittedSince(submission canvas.Submission, t time.Time) bool {
+	submitted, err := parseTime(submission.SubmittedAt)
+	return err == nil && submitted.After(t)
+}
+
+// parseTime reads a time as Canvas writes it, or a date taken as midnight UTC
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// downloadFromUrl downloads an attachment into filePath, refusing any path
+// that would end up outside -outputFolder. The download is checked against
+// the size and content type Canvas gave for the attachment and only replaces
+// the target once it is complete. It returns the size, content type and
+// SHA-256 checksum of the file.
+func downloadFromUrl(client *canvas.Client, attachment canvas.Attachment, filePath string, fileName string) (int64, string, string, error) {
+	target := filepath.Join(filePath, fileName)
+	fmt.Println(target)
+	if err := safepath.Inside(*outputFolder, target); err != nil {
+		return 0, "", "", err
+	}
+
+	var contentType string
+	size, sum, err := writeAtomic(target, func(w io.Writer) error {
+		n, ct, err := client.Download(attachment.URL, w)
+		if err != nil {
+			return err
+		}
+		contentType = ct
+		if attachment.Size > 0 && n != attachment.Size {
+			return fmt.Errorf("got %d bytes, Canvas lists %d for the attachment", n, attachment.Size)
+		}
+		if !sameContentType(attachment.ContentType, ct) {
+			return fmt.Errorf("got content type %s, Canvas lists %s for the attachment", ct, attachment.ContentType)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error while downloading", attachment.URL, "-", err)
+		return 0, "", "", err
+	}
+
+	fmt.Println(size, "bytes downloaded.")
+	if contentType == "" {
+		contentType = attachment.ContentType
+	}
+	return size, contentType, sum, nil
+}
+
+// sameContentType reports whether a download's content type is consistent
+// with the one Canvas lists for the attachment. Parameters such as charset
+// are ignored, and a missing or generic binary type is accepted.
+func sameContentType(expected, got string) bool {
+	expectedType, _, err1 := mime.ParseMediaType(expected)
+	gotType, _, err2 := mime.ParseMediaType(got)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	switch gotType {
+	case "application/octet-stream", "binary/octet-stream", "application/x-download", "application/force-download":
+		return true
+	}
+	return strings.EqualFold(expectedType, gotType)
+}
+
+// writeAtomic writes a file through write into a temporary file next to
+// target, and renames it into place only if write succeeds, so an
+// interrupted or rejected download never leaves a partial file behind. It
+// returns the size and hex SHA-256 checksum of what was written.
+func writeAtomic(target string, write func(w io.Writer) error) (int64, string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, "", err
+	}
+	var size int64
+	var sum string
+	err := atomicfile.Write(target, 0644, func(f *os.File) error {
+		// The file itself is handed to write so a download can empty it and
+		// start over; the checksum is taken once it is complete
+		if err := write(f); err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		size = info.Size()
+		sum, err = fileSHA256(f.Name())
+		return err
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return size, sum, nil
+}
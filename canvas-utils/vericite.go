@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vericite/canvas-utils/canvas"
+	"github.com/vericite/canvas-utils/journal"
+	"github.com/vericite/canvas-utils/report"
+	"github.com/vericite/canvas-utils/rollback"
+)
+
+var visibility *string
+var exclude_quoted *string
+var exclude_self_plag *string
+var store_in_index *string
+
+// var uploadEntry = flag.String("uploadEntry", "true", "Option: Upload entry setting")
+// var textEntry = flag.String("textEntry", "true", "Option: Text entry setting")
+
+func init() {
+	register(&command{
+		group:   "vericite",
+		name:    "enable",
+		summary: "enable VeriCite on the listed assignments",
+		flags: func() {
+			csvFilename = flag.String("filename", "assignments.csv", "a file containing all course ids")
+			visibility = flag.String("visibility", "immediate", "Option: Students Can See the Originality Report")
+			exclude_quoted = flag.String("excludeQuoted", "true", "Option: Exclude Quoted Material")
+			exclude_self_plag = flag.String("excludeSelfPlag", "true", "Option: Exclude Self Plagiarism")
+			store_in_index = flag.String("storeInIndex", "true", "Option: Store submissions in Institutional Index")
+			journalFile = flag.String("journal", "enable-vericite-assignments.journal", "a file recording the outcome of each assignment")
+			resume = flag.Bool("resume", false, "skip assignments the journal records as done and retry the rest")
+			dryRun = flag.Bool("dry-run", false, "print what would change for each assignment without modifying it")
+			rollbackFile = flag.String("rollback", "enable-vericite-assignments.rollback", "a file recording each assignment's settings before it is modified")
+		},
+		run: enableVeriCite,
+	})
+	register(&command{
+		group:   "vericite",
+		name:    "undo",
+		summary: "restore the settings saved by \"vericite enable\"",
+		flags: func() {
+			rollbackFile = flag.String("rollback", "enable-vericite-assignments.rollback", "the rollback file written by vericite enable")
+			dryRun = flag.Bool("dry-run", false, "print what would change for each assignment without modifying it")
+		},
+		run: undoVeriCite,
+	})
+}
+
+func enableVeriCite() {
+	client := newClient()
+
+	file, err := os.Open(*csvFilename)
+	if err != nil {
+		panic("Can not open CSV")
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+
+	//validate parameters:
+	if *visibility != "immediate" &&
+		*visibility != "after_grading" &&
+		*visibility != "after_due_date" &&
+		*visibility != "never" {
+		panic("Visibility parameter can only be one of the following: immediate, after_grading, after_due_date, never")
+	}
+	if *exclude_quoted != "true" && *exclude_quoted != "false" {
+		panic("excludeQuoted can only be true or false")
+	}
+	if *exclude_self_plag != "true" && *exclude_self_plag != "false" {
+		panic("excludeSelfPlag can only be true or false")
+	}
+	if *store_in_index != "true" && *store_in_index != "false" {
+		panic("storeInIndex can only be true or false")
+	}
+	// if(*textEntry != "true" && *textEntry != "false"){
+	// 	panic("textEntry can only be true or false")
+	// }
+	// if(*uploadEntry != "true" && *uploadEntry != "false"){
+	// 	panic("uploadEntry can only be true or false")
+	// }
+	// if(*uploadEntry == "false" && *textEntry == "false"){
+	// 	panic("Either textEntry or uploadEntry must be true")
+	// }
+	// A dry run changes nothing, so it leaves the journal and rollback log alone
+	var runJournal *journal.Journal
+	var rollbackLog *rollback.Log
+	if !*dryRun {
+		runJournal, err = journal.Open(*journalFile, *resume)
+		if err != nil {
+			panic("Can not open journal: " + err.Error())
+		}
+		defer runJournal.Close()
+		rollbackLog, err = rollback.Open(*rollbackFile)
+		if err != nil {
+			panic("Can not open rollback file: " + err.Error())
+		}
+		defer rollbackLog.Close()
+	}
+
+	// Loop through the file containing course IDs
+	logger.Info("VeriCite settings:\nVisibility: " + *visibility + "\nExcludeQuotes: " + *exclude_quoted + "\nExclude Self Plag: " + *exclude_self_plag + "\nStore in Index: " + *store_in_index)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic("Problem reading file")
+		}
+		courseID := record[0]
+		assignmentID := record[1]
+		if _, err := strconv.Atoi(courseID); err != nil {
+			//courseId is not a number, skip
+			continue
+		}
+		start := time.Now()
+		rec := report.Record{Action: "enable-vericite", CourseID: courseID, AssignmentID: assignmentID}
+		if *resume && runJournal != nil && runJournal.Done(courseID, assignmentID) {
+			logger.Debug("Skipping assignment already done: " + assignmentID)
+			rec.Outcome = report.Skipped
+			runReport.Add(rec, start, nil)
+			continue
+		}
+
+		// Here is the correct VeriCite URL
+		data := url.Values{}
+		data.Set("assignment[turnitin_enabled]", "false")
+		data.Set("assignment[vericite_enabled]", "true")
+		data.Set("assignment[turnitin_settings][originality_report_visibility]", *visibility)
+		data.Set("assignment[turnitin_settings][exclude_quoted]", *exclude_quoted)
+		data.Set("assignment[turnitin_settings][exclude_self_plag]", *exclude_self_plag)
+		data.Set("assignment[turnitin_settings][store_in_index]", *store_in_index)
+		// if(*uploadEntry == "true"){
+		// 	data.Add("assignment[submission_types][]", "online_upload")
+		// }
+		// if(*textEntry == "true"){
+		// 	data.Add("assignment[submission_types][]", "online_text_entry")
+		// }
+
+		if *dryRun {
+			rec.Outcome = report.DryRun
+			runReport.Add(rec, start, printDiff(client.WithStatus(&rec.Status), courseID, assignmentID, data))
+			continue
+		}
+
+		// Snapshot the current settings so the change can be undone
+		err = snapshot(client.WithStatus(&rec.Status), rollbackLog, courseID, assignmentID, data)
+		if err != nil {
+			logger.Warning("Could not snapshot assignment: " + assignmentID + ", leaving it unchanged; " + err.Error())
+			runJournal.Record(courseID, assignmentID, journal.Failed, "snapshot: "+err.Error())
+			rec.Action = "snapshot"
+			runReport.Add(rec, start, err)
+			continue
+		}
+
+		// Modify this one assignment
+		_, err = client.WithStatus(&rec.Status).UpdateAssignment(courseID, assignmentID, data)
+		if err == nil {
+			logger.Info("Modified assignment: " + assignmentID)
+			runJournal.Record(courseID, assignmentID, journal.Done, "")
+		} else {
+			logger.Warning("Could not modify assignment: " + assignmentID + "; " + err.Error())
+			if apiErr, ok := err.(*canvas.Error); ok {
+				logger.Warning("Response body: " + apiErr.Body)
+			}
+			runJournal.Record(courseID, assignmentID, journal.Failed, err.Error())
+		}
+		runReport.Add(rec, start, err)
+	}
+}
+
+// snapshot records the assignment's current values of the settings in data
+// in the rollback log
+func snapshot(client *canvas.Client, rollbackLog *rollback.Log, courseID string, assignmentID string, data url.Values) error {
+	assignment, err := client.GetAssignment(courseID, assignmentID)
+	if err != nil {
+		return err
+	}
+	return rollbackLog.Record(courseID, assignmentID, assignment.Snapshot(data))
+}
+
+func undoVeriCite() {
+	// An assignment modified by several runs is restored to the values it
+	// had before the first of them
+	restoreRollback(newClient(), *rollbackFile)
+}
@@ -0,0 +1,186 @@
+package canvas
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Assignment represents an assignment in Canvas
+type Assignment struct {
+	AnonymousPeerReviews           bool                      `json:"anonymous_peer_reviews"`
+	AssignmentGroupID              int                       `json:"assignment_group_id"`
+	AutomaticPeerReviews           bool                      `json:"automatic_peer_reviews"`
+	CourseID                       int                       `json:"course_id"`
+	CreatedAt                      string                    `json:"created_at"`
+	Description                    string                    `json:"description"`
+	DueAt                          interface{}               `json:"due_at"`
+	ExternalToolTagAttributes      ExternalToolTagAttributes `json:"external_tool_tag_attributes"`
+	GradeGroupStudentsIndividually bool                      `json:"grade_group_students_individually"`
+	GradingStandardID              interface{}               `json:"grading_standard_id"`
+	GradingType                    string                    `json:"grading_type"`
+	GroupCategoryID                interface{}               `json:"group_category_id"`
+	HasOverrides                   bool                      `json:"has_overrides"`
+	HasSubmittedSubmissions        bool                      `json:"has_submitted_submissions"`
+	HTMLURL                        string                    `json:"html_url"`
+	ID                             int                       `json:"id"`
+	IntegrationData                struct{}                  `json:"integration_data"`
+	IntegrationID                  interface{}               `json:"integration_id"`
+	LockAt                         interface{}               `json:"lock_at"`
+	LockedForUser                  bool                      `json:"locked_for_user"`
+	ModeratedGrading               bool                      `json:"moderated_grading"`
+	Muted                          bool                      `json:"muted"`
+	Name                           string                    `json:"name"`
+	NeedsGradingCount              int                       `json:"needs_grading_count"`
+	OnlyVisibleToOverrides         bool                      `json:"only_visible_to_overrides"`
+	PeerReviews                    bool                      `json:"peer_reviews"`
+	PointsPossible                 float64                   `json:"points_possible"`
+	Position                       int                       `json:"position"`
+	PostToSis                      interface{}               `json:"post_to_sis"`
+	Published                      bool                      `json:"published"`
+	SubmissionTypes                []string                  `json:"submission_types"`
+	SubmissionsDownloadURL         string                    `json:"submissions_download_url"`
+	UnlockAt                       interface{}               `json:"unlock_at"`
+	Unpublishable                  bool                      `json:"unpublishable"`
+	UpdatedAt                      string                    `json:"updated_at"`
+	URL                            string                    `json:"url"`
+	TurnitinEnabled                bool                      `json:"turnitin_enabled"`
+	VericiteEnabled                bool                      `json:"vericite_enabled"`
+	VeriCiteSettings               *VeriCiteSettings         `json:"turnitin_settings,omitempty"`
+}
+
+// ExternalToolTagAttributes holds the LTI launch settings of an external tool assignment
+type ExternalToolTagAttributes struct {
+	NewTab         bool   `json:"new_tab"`
+	ResourceLinkID string `json:"resource_link_id"`
+	URL            string `json:"url"`
+}
+
+// VeriCiteSettings holds the plagiarism settings Canvas stores under
+// turnitin_settings. Canvas leaves them out for assignments that have never
+// had a plagiarism tool enabled, which leaves Assignment.VeriCiteSettings nil.
+type VeriCiteSettings struct {
+	OriginalityReportVisibility string `json:"originality_report_visibility"`
+	ExcludeQuotes               bool   `json:"exclude_quoted"`
+	ExcludeSelfPlag             bool   `json:"exclude_self_plag"`
+	StoreInIndex                bool   `json:"store_in_index"`
+}
+
+// ListCourseAssignments returns every assignment in a course. On error the
+// assignments fetched so far are returned as well.
+func (c *Client) ListCourseAssignments(courseID string) ([]Assignment, error) {
+	var assignments []Assignment
+	err := c.getAllPages("courses/"+courseID+"/assignments", nil, func(body []byte) error {
+		var page []Assignment
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		assignments = append(assignments, page...)
+		return nil
+	})
+	return assignments, err
+}
+
+// GetAssignment returns a single assignment.
+func (c *Client) GetAssignment(courseID, assignmentID string) (*Assignment, error) {
+	var assignment Assignment
+	if err := c.getJSON("courses/"+courseID+"/assignments/"+assignmentID, nil, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// UpdateAssignment modifies an assignment with form parameters such as
+// "assignment[vericite_enabled]" and returns the updated assignment.
+func (c *Client) UpdateAssignment(courseID, assignmentID string, params url.Values) (*Assignment, error) {
+	var assignment Assignment
+	if err := c.putForm("courses/"+courseID+"/assignments/"+assignmentID, params, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// Change describes how an update parameter differs from an assignment's
+// current value.
+type Change struct {
+	Param string
+	Old   string
+	New   string
+}
+
+// Diff returns the changes that UpdateAssignment with params would make to
+// the assignment, sorted by parameter name. Parameters whose current value
+// is not known, such as turnitin_settings Canvas did not return, are always
+// reported, with Old set to "?".
+func (a *Assignment) Diff(params url.Values) []Change {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changes []Change
+	for _, name := range names {
+		newValue := params.Get(name)
+		oldValue, ok := a.param(name)
+		if !ok {
+			oldValue = "?"
+		}
+		if oldValue != newValue {
+			changes = append(changes, Change{Param: name, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// param returns the current value of an assignment update parameter.
+func (a *Assignment) param(name string) (string, bool) {
+	switch name {
+	case "assignment[name]":
+		return a.Name, true
+	case "assignment[published]":
+		return strconv.FormatBool(a.Published), true
+	case "assignment[turnitin_enabled]":
+		return strconv.FormatBool(a.TurnitinEnabled), true
+	case "assignment[vericite_enabled]":
+		return strconv.FormatBool(a.VericiteEnabled), true
+	case "assignment[external_tool_tag_attributes][url]":
+		return a.ExternalToolTagAttributes.URL, true
+	case "assignment[external_tool_tag_attributes][new_tab]":
+		return strconv.FormatBool(a.ExternalToolTagAttributes.NewTab), true
+	}
+	return a.VeriCiteSettings.param(name)
+}
+
+// param returns the current value of a turnitin_settings update parameter.
+// Settings Canvas did not return are not known.
+func (s *VeriCiteSettings) param(name string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	switch name {
+	case "assignment[turnitin_settings][originality_report_visibility]":
+		return s.OriginalityReportVisibility, true
+	case "assignment[turnitin_settings][exclude_quoted]":
+		return strconv.FormatBool(s.ExcludeQuotes), true
+	case "assignment[turnitin_settings][exclude_self_plag]":
+		return strconv.FormatBool(s.ExcludeSelfPlag), true
+	case "assignment[turnitin_settings][store_in_index]":
+		return strconv.FormatBool(s.StoreInIndex), true
+	}
+	return "", false
+}
+
+// Snapshot returns the assignment's current values for the update parameters
+// in params, so that UpdateAssignment with the result restores them.
+// Parameters whose current value is not known or empty, such as settings
+// Canvas did not return, are left out.
+func (a *Assignment) Snapshot(params url.Values) url.Values {
+	snapshot := url.Values{}
+	for name := range params {
+		if value, ok := a.param(name); ok && value != "" {
+			snapshot.Set(name, value)
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,137 @@
+package canvas
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// decodeAssignment decodes an assignment as Canvas returns it
+func decodeAssignment(t *testing.T, body string) *Assignment {
+	var a Assignment
+	if err := json.Unmarshal([]byte(body), &a); err != nil {
+		t.Fatal(err)
+	}
+	return &a
+}
+
+const withSettings = `{"id":5,"name":"Essay","published":true,"vericite_enabled":false,
+	"external_tool_tag_attributes":{"url":"https://lti.example.com/launch","new_tab":false},
+	"turnitin_settings":{"originality_report_visibility":"after_grading","exclude_quoted":true,"exclude_self_plag":false,"store_in_index":true}}`
+
+const withoutSettings = `{"id":5,"name":"Essay","published":true,"vericite_enabled":false}`
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		desc       string
+		assignment string
+		params     url.Values
+		want       []Change
+	}{
+		{
+			"changes sorted by parameter",
+			withSettings,
+			url.Values{
+				"assignment[vericite_enabled]":                                 {"true"},
+				"assignment[turnitin_settings][originality_report_visibility]": {"immediate"},
+				"assignment[external_tool_tag_attributes][url]":                {"https://app.vericite.com/lti"},
+			},
+			[]Change{
+				{"assignment[external_tool_tag_attributes][url]", "https://lti.example.com/launch", "https://app.vericite.com/lti"},
+				{"assignment[turnitin_settings][originality_report_visibility]", "after_grading", "immediate"},
+				{"assignment[vericite_enabled]", "false", "true"},
+			},
+		},
+		{
+			"unchanged values left out",
+			withSettings,
+			url.Values{
+				"assignment[published]":                         {"true"},
+				"assignment[turnitin_settings][exclude_quoted]": {"true"},
+				"assignment[turnitin_settings][store_in_index]": {"false"},
+			},
+			[]Change{
+				{"assignment[turnitin_settings][store_in_index]", "true", "false"},
+			},
+		},
+		{
+			"no changes",
+			withSettings,
+			url.Values{"assignment[name]": {"Essay"}},
+			nil,
+		},
+		{
+			"turnitin_settings Canvas did not return",
+			withoutSettings,
+			url.Values{
+				"assignment[turnitin_settings][exclude_quoted]":    {"false"},
+				"assignment[turnitin_settings][exclude_self_plag]": {"false"},
+				"assignment[vericite_enabled]":                     {"false"},
+			},
+			[]Change{
+				{"assignment[turnitin_settings][exclude_quoted]", "?", "false"},
+				{"assignment[turnitin_settings][exclude_self_plag]", "?", "false"},
+			},
+		},
+		{
+			"parameters the tool does not know",
+			withSettings,
+			url.Values{"assignment[points_possible]": {"10"}},
+			[]Change{{"assignment[points_possible]", "?", "10"}},
+		},
+	}
+	for _, tt := range tests {
+		got := decodeAssignment(t, tt.assignment).Diff(tt.params)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Diff = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	params := url.Values{
+		"assignment[vericite_enabled]":                                 {"true"},
+		"assignment[external_tool_tag_attributes][url]":                {"https://app.vericite.com/lti"},
+		"assignment[turnitin_settings][originality_report_visibility]": {"immediate"},
+		"assignment[turnitin_settings][exclude_quoted]":                {"false"},
+		"assignment[points_possible]":                                  {"10"},
+	}
+	tests := []struct {
+		desc       string
+		assignment string
+		want       url.Values
+	}{
+		{
+			"every known value",
+			withSettings,
+			url.Values{
+				"assignment[vericite_enabled]":                                 {"false"},
+				"assignment[external_tool_tag_attributes][url]":                {"https://lti.example.com/launch"},
+				"assignment[turnitin_settings][originality_report_visibility]": {"after_grading"},
+				"assignment[turnitin_settings][exclude_quoted]":                {"true"},
+			},
+		},
+		{
+			// recording false for settings Canvas did not return would turn
+			// them off on undo
+			"turnitin_settings Canvas did not return left out",
+			withoutSettings,
+			url.Values{"assignment[vericite_enabled]": {"false"}},
+		},
+		{
+			"empty values left out",
+			`{"id":5,"vericite_enabled":true,"external_tool_tag_attributes":{"url":""},"turnitin_settings":{"originality_report_visibility":"","exclude_quoted":false}}`,
+			url.Values{
+				"assignment[vericite_enabled]":                  {"true"},
+				"assignment[turnitin_settings][exclude_quoted]": {"false"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := decodeAssignment(t, tt.assignment).Snapshot(params)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Snapshot = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
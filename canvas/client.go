@@ -0,0 +1,454 @@
+// Package canvas is a small client for the parts of the Canvas LMS REST API
+// used by the canvas-utils scripts. It can also be imported by other tooling
+// that needs to talk to Canvas the same way the scripts do.
+package canvas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPerPage is the page size requested from Canvas list endpoints.
+const DefaultPerPage = 100
+
+// DefaultTimeout bounds a single HTTP request to Canvas.
+const DefaultTimeout = 60 * time.Second
+
+// DefaultRetries is the number of times a transient failure is retried.
+const DefaultRetries = 3
+
+// DefaultUserAgent is sent with every request unless overridden.
+const DefaultUserAgent = "vericite-canvas-utils"
+
+// Client talks to a single Canvas instance with a single token.
+type Client struct {
+	// BaseURL is the root of the Canvas API, e.g. "https://acmecollege.instructure.com/api/v1/"
+	BaseURL string
+	// Token is the Canvas authentication token sent after the word Bearer
+	Token string
+	// TokenSource, if set, supplies the token for each request instead of Token
+	TokenSource TokenSource
+	// UserAgent is sent in the User-Agent header
+	UserAgent string
+	// PerPage is the number of results requested per page from list endpoints
+	PerPage int
+	// MaxPages is a safety cap on the number of pages followed for one list
+	MaxPages int
+	// HTTPClient performs the requests; set HTTPClient.Timeout to change timeouts
+	HTTPClient *http.Client
+	// DownloadClient performs Download requests; it has no overall timeout
+	// so large files are not cut off. If nil, HTTPClient is used
+	DownloadClient *http.Client
+	// Throttle paces requests against the Canvas rate limit; nil disables it
+	Throttle *Throttle
+	// Retry controls retrying of transient failures; nil disables it
+	Retry *RetryPolicy
+	// Debugf, if set, receives diagnostic messages such as throttling retries
+	Debugf func(format string, args ...interface{})
+
+	// status, if set by WithStatus, receives the status of each response
+	status *int
+}
+
+// NewClient returns a Client for the Canvas API at baseURL using token.
+func NewClient(baseURL, token string) *Client {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &Client{
+		BaseURL:        baseURL,
+		Token:          token,
+		UserAgent:      DefaultUserAgent,
+		PerPage:        DefaultPerPage,
+		MaxPages:       DefaultMaxPages,
+		HTTPClient:     &http.Client{Timeout: DefaultTimeout},
+		DownloadClient: newDownloadClient(),
+		Throttle:       NewThrottle(),
+		Retry:          NewRetryPolicy(DefaultRetries),
+	}
+}
+
+// WithStatus returns a copy of the client that stores the HTTP status of
+// every response it receives in *status, so a caller can report the status
+// of an operation that succeeded as well as of one that failed. *status is 0
+// when the last attempt got no response at all. The copy shares the throttle
+// and retry policy of c; use a separate copy for each concurrent operation.
+func (c *Client) WithStatus(status *int) *Client {
+	tracked := *c
+	tracked.status = status
+	return &tracked
+}
+
+// TokenSource supplies the access token for each request, for example one
+// that refreshes an OAuth2 token when it expires.
+type TokenSource interface {
+	AccessToken() (string, error)
+}
+
+// Error is returned when Canvas answers a request with a non-2xx status.
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// Body is the raw response body, usually a JSON error message from Canvas
+	Body string
+	// Attempts is the number of times the request was sent
+	Attempts int
+}
+
+func (e *Error) Error() string {
+	msg := "canvas: " + e.Method + " " + e.URL + ": " + e.Status
+	if e.Attempts > 1 {
+		msg += " (after " + strconv.Itoa(e.Attempts) + " attempts)"
+	}
+	return msg
+}
+
+// Message returns the error message(s) Canvas gave in the response body,
+// which comes in several shapes depending on the endpoint:
+//
+//	{"errors":[{"message":"The specified resource does not exist."}]}
+//	{"errors":{"name":[{"attribute":"name","type":"blank","message":"blank"}]}}
+//	{"message":"Invalid access token."}
+//
+// A body that is not JSON is returned as is, cut to 200 characters.
+func (e *Error) Message() string {
+	var body interface{}
+	if err := json.Unmarshal([]byte(e.Body), &body); err != nil {
+		msg := strings.TrimSpace(e.Body)
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		return msg
+	}
+	return strings.Join(errorMessages(body, ""), "; ")
+}
+
+// errorMessages collects the "message" strings in a decoded error body,
+// prefixed with the attribute they are about when there is one
+func errorMessages(v interface{}, attribute string) []string {
+	var msgs []string
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			msgs = append(msgs, errorMessages(item, attribute)...)
+		}
+	case map[string]interface{}:
+		if msg, ok := v["message"].(string); ok {
+			if attr, ok := v["attribute"].(string); ok {
+				attribute = attr
+			}
+			if attribute != "" {
+				msg = attribute + ": " + msg
+			}
+			return append(msgs, msg)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			next := attribute
+			if key != "errors" {
+				next = key
+			}
+			msgs = append(msgs, errorMessages(v[key], next)...)
+		}
+	}
+	return msgs
+}
+
+func (c *Client) newRequest(method, path string, query url.Values, body []byte) (*http.Request, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return c.newRequestURL(method, u, body)
+}
+
+// newRequestURL builds an authenticated request for an absolute URL, such as
+// a pagination link returned by Canvas.
+func (c *Client) newRequestURL(method, u string, body []byte) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	token := c.Token
+	if c.TokenSource != nil {
+		if token, err = c.TokenSource.AccessToken(); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return req, nil
+}
+
+// do sends req and returns the response along with its fully read body.
+// Requests are paced by the client's Throttle and retried when Canvas
+// rejects them for exceeding the rate limit, or when they fail for a
+// transient reason allowed by the client's Retry policy. Non-2xx responses
+// are returned as an *Error and transport failures as a *RequestError.
+func (c *Client) do(req *http.Request) (*http.Response, []byte, error) {
+	attempts, throttled, retries := 0, 0, 0
+	for {
+		if c.Throttle != nil {
+			c.Throttle.Wait()
+		}
+		attempts++
+		resp, body, err := c.send(req)
+		c.recordStatus(resp)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if c.Retry == nil || retries >= c.Retry.MaxRetries {
+				return resp, body, &RequestError{Method: req.Method, URL: req.URL.String(), Attempts: attempts, Err: err}
+			}
+			retries++
+			wait = c.Retry.Backoff(retries)
+			c.debugf("canvas: %s %s failed: %s, retrying in %s", req.Method, req.URL, err, wait)
+		case c.Throttle != nil && isRateLimited(resp, body) && throttled < c.Throttle.MaxRetries:
+			c.Throttle.Update(resp.Header)
+			throttled++
+			wait = c.Throttle.Backoff(throttled)
+			c.debugf("canvas: rate limit exceeded on %s %s, retrying in %s", req.Method, req.URL, wait)
+		case isTransientStatus(resp.StatusCode) && c.Retry != nil && retries < c.Retry.MaxRetries:
+			retries++
+			wait = c.Retry.Backoff(retries)
+			if ra := retryAfter(resp); ra > wait {
+				wait = ra
+			}
+			c.debugf("canvas: %s %s returned %s, retrying in %s", req.Method, req.URL, resp.Status, wait)
+		default:
+			if c.Throttle != nil {
+				c.Throttle.Update(resp.Header)
+			}
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return resp, body, &Error{
+					Method:     req.Method,
+					URL:        req.URL.String(),
+					StatusCode: resp.StatusCode,
+					Status:     resp.Status,
+					Body:       string(body),
+					Attempts:   attempts,
+				}
+			}
+			return resp, body, nil
+		}
+		time.Sleep(wait)
+		r, err := c.rewind(req)
+		if err != nil {
+			return nil, nil, &RequestError{Method: req.Method, URL: req.URL.String(), Attempts: attempts, Err: err}
+		}
+		req = r
+	}
+}
+
+// send performs a single round trip and reads the whole response body.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	return resp, body, err
+}
+
+// rewind returns a copy of req with its body reset so it can be sent again.
+// The token is fetched again in case it expired while waiting to retry.
+func (c *Client) rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	if c.TokenSource != nil && r.Header.Get("Authorization") != "" {
+		token, err := c.TokenSource.AccessToken()
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r, nil
+}
+
+// recordStatus stores the status of resp for WithStatus, or 0 if there was
+// no response.
+func (c *Client) recordStatus(resp *http.Response) {
+	if c.status == nil {
+		return
+	}
+	*c.status = 0
+	if resp != nil {
+		*c.status = resp.StatusCode
+	}
+}
+
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.Debugf != nil {
+		c.Debugf(format, args...)
+	}
+}
+
+// getJSON fetches path and decodes the JSON response into v.
+func (c *Client) getJSON(path string, query url.Values, v interface{}) error {
+	req, err := c.newRequest("GET", path, query, nil)
+	if err != nil {
+		return err
+	}
+	_, body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// putForm sends params form-encoded to path and decodes the JSON response into v.
+func (c *Client) putForm(path string, params url.Values, v interface{}) error {
+	req, err := c.newRequest("PUT", path, nil, []byte(params.Encode()))
+	if err != nil {
+		return err
+	}
+	_, body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// getAllPages fetches every page of a list endpoint, handing each page's body
+// to decode.
+func (c *Client) getAllPages(path string, query url.Values, decode func(body []byte) error) error {
+	p := c.NewPaginator(path, query)
+	for p.HasNext() {
+		body, err := p.Next()
+		if err != nil {
+			return err
+		}
+		if err := decode(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Download fetches a file URL, such as an attachment URL returned by Canvas,
+// and copies it to w. It returns the number of bytes copied and the
+// Content-Type of the response. A non-2xx response is returned as *Error and
+// nothing is written to w, so an error page is never taken for the file.
+// Attachment URLs carry their own verifier so no token is sent.
+//
+// Downloads go through DownloadClient and are retried according to the
+// client's Retry policy. A download that fails partway through is only
+// retried if w can be emptied first, as an *os.File can; otherwise the
+// partial copy is returned with the error.
+func (c *Client) Download(fileURL string, w io.Writer) (int64, string, error) {
+	req, err := http.NewRequest("GET", fileURL, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	attempts, retries := 0, 0
+	for {
+		attempts++
+		resp, n, err := c.download(req, w)
+		c.recordStatus(resp)
+		if err == nil {
+			return n, resp.Header.Get("Content-Type"), nil
+		}
+		apiErr, isStatus := err.(*Error)
+		if isStatus {
+			apiErr.Attempts = attempts
+		} else {
+			err = &RequestError{Method: req.Method, URL: fileURL, Attempts: attempts, Err: err}
+		}
+		if (isStatus && !isTransientStatus(apiErr.StatusCode)) || c.Retry == nil || retries >= c.Retry.MaxRetries {
+			return n, "", err
+		}
+		if n > 0 {
+			if rerr := truncate(w); rerr != nil {
+				return n, "", err
+			}
+		}
+		retries++
+		wait := c.Retry.Backoff(retries)
+		if ra := retryAfter(resp); isStatus && ra > wait {
+			wait = ra
+		}
+		c.debugf("canvas: download of %s failed: %s, retrying in %s", fileURL, err, wait)
+		time.Sleep(wait)
+	}
+}
+
+// download performs a single download attempt, returning the response even
+// when its status is an error so a Retry-After header can be honoured.
+func (c *Client) download(req *http.Request, w io.Writer) (*http.Response, int64, error) {
+	httpClient := c.DownloadClient
+	if httpClient == nil {
+		httpClient = c.HTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return resp, 0, &Error{Method: req.Method, URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body), Attempts: 1}
+	}
+	n, err := io.Copy(w, resp.Body)
+	return resp, n, err
+}
+
+// truncate empties w so a failed download can be started over. Writers that
+// can not be emptied return an error.
+func truncate(w io.Writer) error {
+	t, ok := w.(interface {
+		io.Seeker
+		Truncate(size int64) error
+	})
+	if !ok {
+		return errors.New("canvas: download writer can not be reset")
+	}
+	if err := t.Truncate(0); err != nil {
+		return err
+	}
+	_, err := t.Seek(0, io.SeekStart)
+	return err
+}
+
+// newDownloadClient returns the HTTP client used for downloads. It has no
+// overall timeout, which would also cut off reading a large file, but gives
+// up on a server that does not start answering within DefaultTimeout.
+func newDownloadClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = DefaultTimeout
+	return &http.Client{Transport: transport}
+}
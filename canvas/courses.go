@@ -0,0 +1,85 @@
+package canvas
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// Course represents a course in Canvas
+type Course struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	CourseCode       string `json:"course_code"`
+	AccountID        int    `json:"account_id"`
+	EnrollmentTermID int    `json:"enrollment_term_id"`
+	WorkflowState    string `json:"workflow_state"`
+	Blueprint        bool   `json:"blueprint"`
+}
+
+// CourseFilter narrows the courses listed by ListAccountCourses. Empty fields
+// are not sent. The yes/no filters take "true" or "false".
+type CourseFilter struct {
+	// TermID is the enrollment term id
+	TermID string
+	// WithEnrollments keeps only courses with (true) or without (false) enrollments
+	WithEnrollments string
+	// Published keeps only published (true) or unpublished (false) courses
+	Published string
+	// Completed keeps only completed (true) or not completed (false) courses
+	Completed string
+	// Blueprint keeps only blueprint (true) or non-blueprint (false) courses
+	Blueprint string
+	// SearchTerm matches course names, codes and SIS ids
+	SearchTerm string
+	// State keeps only courses in these workflow states: created, claimed,
+	// available, completed, deleted or all
+	State []string
+	// BySubaccounts keeps only courses in these sub-accounts
+	BySubaccounts []string
+}
+
+func (f CourseFilter) query() url.Values {
+	query := url.Values{}
+	set := func(name, value string) {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	set("enrollment_term_id", f.TermID)
+	set("with_enrollments", f.WithEnrollments)
+	set("published", f.Published)
+	set("completed", f.Completed)
+	set("blueprint", f.Blueprint)
+	set("search_term", f.SearchTerm)
+	for _, state := range f.State {
+		query.Add("state[]", state)
+	}
+	for _, id := range f.BySubaccounts {
+		query.Add("by_subaccounts[]", id)
+	}
+	return query
+}
+
+// ListAccountCourses returns the courses of an account matching filter. On
+// error the courses fetched so far are returned as well.
+func (c *Client) ListAccountCourses(accountID string, filter CourseFilter) ([]Course, error) {
+	var courses []Course
+	err := c.getAllPages("accounts/"+accountID+"/courses", filter.query(), func(body []byte) error {
+		var page []Course
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		courses = append(courses, page...)
+		return nil
+	})
+	return courses, err
+}
+
+// GetCourse returns a single course.
+func (c *Client) GetCourse(courseID string) (*Course, error) {
+	var course Course
+	if err := c.getJSON("courses/"+courseID, nil, &course); err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
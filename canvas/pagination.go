@@ -0,0 +1,141 @@
+package canvas
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxPages is the default safety cap on pages followed for one list.
+// At DefaultPerPage results per page this allows 100,000 records.
+const DefaultMaxPages = 1000
+
+// PageLimitError is returned when a list has more pages than Client.MaxPages.
+// Cursor can be handed to Client.ResumePaginator to continue from there.
+type PageLimitError struct {
+	URL    string
+	Pages  int
+	Cursor string
+}
+
+func (e *PageLimitError) Error() string {
+	return "canvas: stopped after " + strconv.Itoa(e.Pages) + " pages of " + e.URL + "; raise MaxPages to fetch the rest"
+}
+
+// LinkError is returned when the Link header of a page points the next page
+// somewhere the token must not be sent, such as another host, or can not be
+// parsed. The link is not followed and the list ends there.
+type LinkError struct {
+	URL  string
+	Link string
+}
+
+func (e *LinkError) Error() string {
+	return "canvas: invalid next link " + e.Link + " in " + e.URL
+}
+
+// Paginator walks a Canvas list endpoint by following the rel="next" URL of
+// the Link header Canvas returns with every page. Both numbered and
+// bookmark-style ("page=bookmark:...") pages are followed verbatim.
+type Paginator struct {
+	client *Client
+	first  string
+	next   string
+	pages  int
+}
+
+// NewPaginator returns a Paginator for the list endpoint at path.
+func (c *Client) NewPaginator(path string, query url.Values) *Paginator {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("per_page", strconv.Itoa(c.PerPage))
+	u := c.BaseURL + path + "?" + q.Encode()
+	return &Paginator{client: c, first: u, next: u}
+}
+
+// ResumePaginator returns a Paginator that starts at cursor, a URL previously
+// returned by Paginator.Cursor or PageLimitError.Cursor.
+func (c *Client) ResumePaginator(cursor string) *Paginator {
+	return &Paginator{client: c, first: cursor, next: cursor}
+}
+
+// HasNext reports whether there is another page to fetch.
+func (p *Paginator) HasNext() bool {
+	return p.next != ""
+}
+
+// Cursor returns the URL of the next page, or "" once the last page was read.
+func (p *Paginator) Cursor() string {
+	return p.next
+}
+
+// Next fetches the next page and returns its body.
+func (p *Paginator) Next() ([]byte, error) {
+	max := p.client.MaxPages
+	if max <= 0 {
+		max = DefaultMaxPages
+	}
+	if p.pages >= max {
+		return nil, &PageLimitError{URL: p.first, Pages: p.pages, Cursor: p.next}
+	}
+	req, err := p.client.newRequestURL("GET", p.next, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := p.client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	p.pages++
+	next := parseLinkHeader(strings.Join(resp.Header["Link"], ","))["next"]
+	if next != "" {
+		// never send the token to a host other than the one we started on
+		nu, err := req.URL.Parse(next)
+		if err != nil || nu.Host != req.URL.Host {
+			p.next = ""
+			return nil, &LinkError{URL: req.URL.String(), Link: next}
+		}
+		next = nu.String()
+	}
+	p.next = next
+	return body, nil
+}
+
+// parseLinkHeader parses an RFC 5988 Link header such as
+//
+//	<https://x/api/v1/courses?page=2>; rel="next", <https://x/api/v1/courses?page=1>; rel="first"
+//
+// into a map from rel to URL. Links are split at their angle brackets rather
+// than at commas, since the URLs themselves may contain commas.
+func parseLinkHeader(header string) map[string]string {
+	links := map[string]string{}
+	for {
+		start := strings.IndexByte(header, '<')
+		if start < 0 {
+			return links
+		}
+		end := strings.IndexByte(header[start:], '>')
+		if end < 0 {
+			return links
+		}
+		target := header[start+1 : start+end]
+		header = header[start+end+1:]
+		// the parameters of this link run up to the next one
+		params := header
+		if next := strings.IndexByte(header, '<'); next >= 0 {
+			params = header[:next]
+		}
+		for _, param := range strings.Split(params, ";") {
+			param = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(param), ","))
+			kv := strings.SplitN(param, "=", 2)
+			if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "rel" {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(kv[1]), `"`)) {
+				links[strings.ToLower(rel)] = target
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package canvas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   map[string]string
+	}{
+		{
+			`<https://x/api/v1/courses?page=2&per_page=10>; rel="current", <https://x/api/v1/courses?page=3&per_page=10>; rel="next", <https://x/api/v1/courses?page=1&per_page=10>; rel="first", <https://x/api/v1/courses?page=9&per_page=10>; rel="last"`,
+			map[string]string{
+				"current": "https://x/api/v1/courses?page=2&per_page=10",
+				"next":    "https://x/api/v1/courses?page=3&per_page=10",
+				"first":   "https://x/api/v1/courses?page=1&per_page=10",
+				"last":    "https://x/api/v1/courses?page=9&per_page=10",
+			},
+		},
+		{
+			`<https://x/api/v1/courses?page=bookmark:WzEyMzRd>; rel="next",<https://x/api/v1/courses?page=first>; rel="first"`,
+			map[string]string{
+				"next":  "https://x/api/v1/courses?page=bookmark:WzEyMzRd",
+				"first": "https://x/api/v1/courses?page=first",
+			},
+		},
+		{
+			// commas inside the URL do not split it
+			`<https://x/api/v1/courses?include[]=term,teachers&page=2>; rel="next", <https://x/api/v1/courses?include[]=term,teachers&page=1>; rel="first"`,
+			map[string]string{
+				"next":  "https://x/api/v1/courses?include[]=term,teachers&page=2",
+				"first": "https://x/api/v1/courses?include[]=term,teachers&page=1",
+			},
+		},
+		{
+			// several rels, unquoted rels, upper case and other parameters
+			`<https://x/a?page=5>; type="application/json"; rel="next last", <https://x/a?page=1>; REL=First`,
+			map[string]string{
+				"next":  "https://x/a?page=5",
+				"last":  "https://x/a?page=5",
+				"first": "https://x/a?page=1",
+			},
+		},
+		{`<https://x/a?page=2>`, map[string]string{}},
+		{`https://x/a?page=2; rel="next"`, map[string]string{}},
+		{``, map[string]string{}},
+	}
+	for _, tt := range tests {
+		if got := parseLinkHeader(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLinkHeader(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+// listServer serves a list of pages at /api/v1/items; next returns the Link
+// header URL of the page after page, or "" after the last one
+func listServer(t *testing.T, pages int, next func(host string, page int) string) (*httptest.Server, *[]string) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(strings.TrimPrefix(p, "bookmark:"))
+		}
+		if page < pages {
+			if link := next(r.Host, page); link != "" {
+				w.Header().Set("Link", "<"+link+`>; rel="next"`)
+			}
+		}
+		w.Write([]byte(`[` + strconv.Itoa(page) + `]`))
+	}))
+	return srv, &requested
+}
+
+// readAll reads every page of p, returning the bodies joined
+func readAll(t *testing.T, p *Paginator) string {
+	var bodies []string
+	for p.HasNext() {
+		body, err := p.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		bodies = append(bodies, string(body))
+	}
+	return strings.Join(bodies, "")
+}
+
+func TestPaginatorFollowsLinks(t *testing.T) {
+	srv, requested := listServer(t, 3, func(host string, page int) string {
+		return "http://" + host + "/api/v1/items?page=" + strconv.Itoa(page+1) + "&per_page=100"
+	})
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v1", "token")
+
+	p := c.NewPaginator("items", nil)
+	if got := readAll(t, p); got != "[1][2][3]" {
+		t.Errorf("pages = %s, want [1][2][3]", got)
+	}
+	want := []string{"/api/v1/items?per_page=100", "/api/v1/items?page=2&per_page=100", "/api/v1/items?page=3&per_page=100"}
+	if !reflect.DeepEqual(*requested, want) {
+		t.Errorf("requested %v, want %v", *requested, want)
+	}
+	if p.Cursor() != "" {
+		t.Errorf("Cursor after the last page = %q, want empty", p.Cursor())
+	}
+}
+
+func TestPaginatorBookmarks(t *testing.T) {
+	// Canvas bookmarks are opaque and relative links are resolved against
+	// the page they came from
+	srv, requested := listServer(t, 3, func(host string, page int) string {
+		return "items?page=bookmark:" + strconv.Itoa(page+1)
+	})
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v1/", "token")
+
+	if got := readAll(t, c.NewPaginator("items", nil)); got != "[1][2][3]" {
+		t.Errorf("pages = %s, want [1][2][3]", got)
+	}
+	want := []string{"/api/v1/items?per_page=100", "/api/v1/items?page=bookmark:2", "/api/v1/items?page=bookmark:3"}
+	if !reflect.DeepEqual(*requested, want) {
+		t.Errorf("requested %v, want %v", *requested, want)
+	}
+}
+
+func TestPaginatorPageLimit(t *testing.T) {
+	srv, _ := listServer(t, 5, func(host string, page int) string {
+		return "http://" + host + "/api/v1/items?page=" + strconv.Itoa(page+1)
+	})
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v1/", "token")
+	c.MaxPages = 2
+
+	p := c.NewPaginator("items", nil)
+	for i := 0; i < 2; i++ {
+		if _, err := p.Next(); err != nil {
+			t.Fatalf("page %d: %v", i+1, err)
+		}
+	}
+	_, err := p.Next()
+	limitErr, ok := err.(*PageLimitError)
+	if !ok {
+		t.Fatalf("third page error = %v, want *PageLimitError", err)
+	}
+	if limitErr.Pages != 2 || !strings.HasSuffix(limitErr.Cursor, "/api/v1/items?page=3") || !strings.HasSuffix(limitErr.URL, "/api/v1/items?per_page=100") {
+		t.Errorf("PageLimitError = %+v", limitErr)
+	}
+
+	// The cursor picks up where the limit stopped
+	c.MaxPages = 0
+	if got := readAll(t, c.ResumePaginator(limitErr.Cursor)); got != "[3][4][5]" {
+		t.Errorf("resumed pages = %s, want [3][4][5]", got)
+	}
+}
+
+func TestPaginatorRejectsOtherHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent to the other host: %s (Authorization %q)", r.URL, r.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+	srv, _ := listServer(t, 3, func(host string, page int) string {
+		return other.URL + "/api/v1/items?page=" + strconv.Itoa(page+1)
+	})
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v1/", "token")
+
+	p := c.NewPaginator("items", nil)
+	_, err := p.Next()
+	linkErr, ok := err.(*LinkError)
+	if !ok {
+		t.Fatalf("Next error = %v, want *LinkError", err)
+	}
+	if !strings.HasPrefix(linkErr.Link, other.URL) || !strings.HasSuffix(linkErr.URL, "/api/v1/items?per_page=100") {
+		t.Errorf("LinkError = %+v", linkErr)
+	}
+	// the list ends at the rejected link rather than fetching the page again
+	if p.HasNext() || p.Cursor() != "" {
+		t.Errorf("HasNext = %v, Cursor = %q after a rejected link", p.HasNext(), p.Cursor())
+	}
+}
@@ -0,0 +1,102 @@
+package canvas
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Throttle paces requests against Canvas's leaky-bucket rate limit. Canvas
+// reports the quota left in the X-Rate-Limit-Remaining header and the cost of
+// the request just made in X-Request-Cost; once the remaining quota drops
+// below LowWater each request is delayed, the longer the lower the bucket.
+// A Throttle is safe for concurrent use and is meant to be shared by every
+// request made with the same token.
+type Throttle struct {
+	// LowWater is the remaining quota below which requests are delayed
+	LowWater float64
+	// MaxDelay is the delay applied when the bucket is empty
+	MaxDelay time.Duration
+	// MaxRetries is how many times a request rejected with 403 Rate Limit
+	// Exceeded is retried before the error is returned
+	MaxRetries int
+	// RetryDelay is the wait before the first retry of a throttled request;
+	// it doubles with every further retry up to MaxDelay
+	RetryDelay time.Duration
+
+	mu        sync.Mutex
+	known     bool
+	remaining float64
+	cost      float64
+}
+
+// NewThrottle returns a Throttle with defaults suited to Canvas's standard
+// bucket of 700 units.
+func NewThrottle() *Throttle {
+	return &Throttle{
+		LowWater:   300,
+		MaxDelay:   10 * time.Second,
+		MaxRetries: 5,
+		RetryDelay: 2 * time.Second,
+	}
+}
+
+// Delay returns how long to wait before sending the next request.
+func (t *Throttle) Delay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.known || t.LowWater <= 0 {
+		return 0
+	}
+	// budget for the cost of the next request, assuming it is like the last
+	left := t.remaining - t.cost
+	if left >= t.LowWater {
+		return 0
+	}
+	if left < 0 {
+		left = 0
+	}
+	return time.Duration(float64(t.MaxDelay) * (t.LowWater - left) / t.LowWater)
+}
+
+// Wait sleeps for Delay.
+func (t *Throttle) Wait() {
+	if d := t.Delay(); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// Update records the rate limit headers of a Canvas response.
+func (t *Throttle) Update(h http.Header) {
+	remaining, err := strconv.ParseFloat(h.Get("X-Rate-Limit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+	cost, _ := strconv.ParseFloat(h.Get("X-Request-Cost"), 64)
+	t.mu.Lock()
+	t.known = true
+	t.remaining = remaining
+	t.cost = cost
+	t.mu.Unlock()
+}
+
+// Backoff returns the wait before retry number attempt (starting at 1) of a
+// throttled request.
+func (t *Throttle) Backoff(attempt int) time.Duration {
+	d := t.RetryDelay
+	for i := 1; i < attempt && d < t.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.MaxDelay {
+		d = t.MaxDelay
+	}
+	return d
+}
+
+// isRateLimited reports whether Canvas rejected a request for exceeding the
+// rate limit, which it signals with 403 and "Rate Limit Exceeded" in the body.
+func isRateLimited(resp *http.Response, body []byte) bool {
+	return resp.StatusCode == http.StatusForbidden && strings.Contains(string(body), "Rate Limit Exceeded")
+}
@@ -0,0 +1,138 @@
+package canvas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottleDelay(t *testing.T) {
+	tests := []struct {
+		desc      string
+		remaining string
+		cost      string
+		want      time.Duration
+	}{
+		{"no headers yet", "", "", 0},
+		{"full bucket", "700", "1", 0},
+		{"just above low water", "301", "1", 0},
+		{"half way below low water", "150", "0", 5 * time.Second},
+		{"cost of the next request counted", "200", "50", 5 * time.Second},
+		{"empty bucket", "0", "0", 10 * time.Second},
+		{"overdrawn bucket", "10", "30", 10 * time.Second},
+		{"unreadable remaining", "lots", "1", 0},
+	}
+	for _, tt := range tests {
+		throttle := NewThrottle()
+		h := http.Header{}
+		if tt.remaining != "" {
+			h.Set("X-Rate-Limit-Remaining", tt.remaining)
+			h.Set("X-Request-Cost", tt.cost)
+		}
+		throttle.Update(h)
+		if got := throttle.Delay(); got != tt.want {
+			t.Errorf("%s: Delay() = %s, want %s", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestThrottleUpdateKeepsLastKnown(t *testing.T) {
+	throttle := NewThrottle()
+	throttle.Update(http.Header{"X-Rate-Limit-Remaining": {"0"}})
+	// a response without the headers, such as a file download, changes nothing
+	throttle.Update(http.Header{})
+	if got := throttle.Delay(); got != throttle.MaxDelay {
+		t.Errorf("Delay() = %s, want %s", got, throttle.MaxDelay)
+	}
+	throttle.LowWater = 0
+	if got := throttle.Delay(); got != 0 {
+		t.Errorf("Delay() with LowWater 0 = %s, want 0", got)
+	}
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	throttle := NewThrottle()
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := throttle.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+// rateLimitServer answers with 403 Rate Limit Exceeded the first limited
+// times, then with a course
+func rateLimitServer(limited int32, body string) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= limited {
+			w.Header().Set("X-Rate-Limit-Remaining", "0")
+			http.Error(w, body, http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-Rate-Limit-Remaining", "700")
+		w.Write([]byte(`{"id":1,"name":"Biology"}`))
+	}))
+	return srv, &requests
+}
+
+// fastClient returns a client for srv that waits at most a millisecond
+// between attempts
+func fastClient(srv *httptest.Server) *Client {
+	c := NewClient(srv.URL+"/api/v1/", "token")
+	c.Throttle.RetryDelay = time.Millisecond
+	c.Throttle.MaxDelay = time.Millisecond
+	c.Retry.BaseDelay = time.Millisecond
+	c.Retry.MaxDelay = time.Millisecond
+	return c
+}
+
+func TestDoThrottled(t *testing.T) {
+	tests := []struct {
+		desc     string
+		limited  int32
+		body     string
+		status   int
+		requests int32
+	}{
+		{"succeeds after two rate limit rejections", 2, "403 Forbidden (Rate Limit Exceeded)", 0, 3},
+		{"gives up after MaxRetries", 10, "403 Forbidden (Rate Limit Exceeded)", 403, 6},
+		{"other 403s are not retried", 10, "user not authorized to perform that action", 403, 1},
+	}
+	for _, tt := range tests {
+		srv, requests := rateLimitServer(tt.limited, tt.body)
+		c := fastClient(srv)
+		course, err := c.GetCourse("1")
+		srv.Close()
+		if tt.status == 0 {
+			if err != nil || course.Name != "Biology" {
+				t.Errorf("%s: GetCourse = %v, %v", tt.desc, course, err)
+			}
+		} else {
+			apiErr, ok := err.(*Error)
+			if !ok || apiErr.StatusCode != tt.status || int32(apiErr.Attempts) != tt.requests {
+				t.Errorf("%s: GetCourse error = %#v, want status %d after %d attempts", tt.desc, err, tt.status, tt.requests)
+			}
+		}
+		if *requests != tt.requests {
+			t.Errorf("%s: %d requests, want %d", tt.desc, *requests, tt.requests)
+		}
+	}
+}
+
+func TestDoThrottleDisabled(t *testing.T) {
+	srv, requests := rateLimitServer(1, "403 Forbidden (Rate Limit Exceeded)")
+	defer srv.Close()
+	c := fastClient(srv)
+	c.Throttle = nil
+	_, err := c.GetCourse("1")
+	if apiErr, ok := err.(*Error); !ok || !strings.Contains(apiErr.Body, "Rate Limit Exceeded") {
+		t.Errorf("GetCourse error = %v, want the rate limit error", err)
+	}
+	if *requests != 1 {
+		t.Errorf("%d requests, want 1", *requests)
+	}
+}
@@ -0,0 +1,86 @@
+package canvas
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail for transient reasons are
+// retried: connection errors, timeouts, 429 Too Many Requests and 5xx
+// responses. The delay before retry n is a random duration between half and
+// all of BaseDelay * 2^(n-1), capped at MaxDelay.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; 0 disables retrying
+	MaxRetries int
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts
+	MaxDelay time.Duration
+}
+
+// NewRetryPolicy returns a RetryPolicy retrying up to maxRetries times with
+// the default delays.
+func NewRetryPolicy(maxRetries int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: maxRetries,
+		BaseDelay:  1 * time.Second,
+		MaxDelay:   60 * time.Second,
+	}
+}
+
+// Backoff returns the jittered wait before retry number attempt (starting at 1).
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// RequestError is returned when a request could not be completed at all, for
+// example because the connection failed or timed out on every attempt.
+type RequestError struct {
+	Method   string
+	URL      string
+	Attempts int
+	Err      error
+}
+
+func (e *RequestError) Error() string {
+	msg := "canvas: " + e.Method + " " + e.URL + ": " + e.Err.Error()
+	if e.Attempts > 1 {
+		msg += " (after " + strconv.Itoa(e.Attempts) + " attempts)"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying transport error.
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// isTransientStatus reports whether a response status is worth retrying.
+func isTransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter returns the delay requested by a Retry-After header given in
+// seconds, or 0 if there is none.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
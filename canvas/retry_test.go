@@ -0,0 +1,225 @@
+package canvas
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{4, 2500 * time.Millisecond, 5 * time.Second},
+		{10, 2500 * time.Millisecond, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := p.Backoff(tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("Backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+	if got := (&RetryPolicy{}).Backoff(1); got != 0 {
+		t.Errorf("Backoff with no delay = %s, want 0", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"0", 0},
+		{"-1", 0},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		if got := retryAfter(resp); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+	if got := retryAfter(nil); got != 0 {
+		t.Errorf("retryAfter(nil) = %s, want 0", got)
+	}
+}
+
+// statusServer answers the requests in turn with the given statuses, then
+// with 200 and an assignment; each body it receives is appended to bodies
+func statusServer(statuses []int, bodies *[]string) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if bodies != nil {
+			body, _ := ioutil.ReadAll(r.Body)
+			*bodies = append(*bodies, string(body))
+		}
+		if n <= len(statuses) {
+			http.Error(w, `{"errors":[{"message":"status `+strconv.Itoa(statuses[n-1])+`"}]}`, statuses[n-1])
+			return
+		}
+		w.Write([]byte(`{"id":5,"name":"Essay"}`))
+	}))
+	return srv, &requests
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statuses   []int
+		maxRetries int
+		status     int
+		requests   int32
+	}{
+		{"success at once", nil, 3, 0, 1},
+		{"5xx and 429 retried", []int{503, 500, 429}, 3, 0, 4},
+		{"retries exhausted", []int{502, 502, 502, 502, 502}, 3, 502, 4},
+		{"no retries", []int{503}, 0, 503, 1},
+		{"4xx not retried", []int{404}, 3, 404, 1},
+		{"422 not retried", []int{422}, 3, 422, 1},
+	}
+	for _, tt := range tests {
+		srv, requests := statusServer(tt.statuses, nil)
+		c := fastClient(srv)
+		c.Retry.MaxRetries = tt.maxRetries
+		assignment, err := c.GetAssignment("1", "5")
+		srv.Close()
+		if tt.status == 0 {
+			if err != nil || assignment.Name != "Essay" {
+				t.Errorf("%s: GetAssignment = %v, %v", tt.desc, assignment, err)
+			}
+		} else {
+			apiErr, ok := err.(*Error)
+			if !ok || apiErr.StatusCode != tt.status || int32(apiErr.Attempts) != tt.requests {
+				t.Errorf("%s: GetAssignment error = %#v, want status %d after %d attempts", tt.desc, err, tt.status, tt.requests)
+			} else if apiErr.Message() != "status "+strconv.Itoa(tt.status) {
+				t.Errorf("%s: Message() = %q", tt.desc, apiErr.Message())
+			}
+		}
+		if *requests != tt.requests {
+			t.Errorf("%s: %d requests, want %d", tt.desc, *requests, tt.requests)
+		}
+	}
+}
+
+func TestDoRetryResendsBody(t *testing.T) {
+	var bodies []string
+	srv, _ := statusServer([]int{503, 503}, &bodies)
+	defer srv.Close()
+	c := fastClient(srv)
+	params := url.Values{"assignment[vericite_enabled]": {"true"}}
+	if _, err := c.UpdateAssignment("1", "5", params); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("%d requests, want 3", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != params.Encode() {
+			t.Errorf("attempt %d sent %q, want %q", i+1, body, params.Encode())
+		}
+	}
+}
+
+// countingTokens hands out a new token on every call
+type countingTokens struct{ n int32 }
+
+func (c *countingTokens) AccessToken() (string, error) {
+	return "token" + strconv.Itoa(int(atomic.AddInt32(&c.n, 1))), nil
+}
+
+func TestDoRetryRefreshesToken(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		if len(seen) == 1 {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := fastClient(srv)
+	c.TokenSource = &countingTokens{}
+	if _, err := c.GetCourse("1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 2 || seen[0] != "Bearer token1" || seen[1] != "Bearer token2" {
+		t.Errorf("Authorization headers = %q, want a fresh token on the retry", seen)
+	}
+}
+
+func TestDoRetryTransportError(t *testing.T) {
+	// a server that is gone refuses every connection
+	srv := httptest.NewServer(http.NotFoundHandler())
+	c := fastClient(srv)
+	srv.Close()
+	c.Retry.MaxRetries = 2
+	_, err := c.GetCourse("1")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Attempts != 3 || reqErr.Method != "GET" {
+		t.Fatalf("GetCourse error = %#v, want a *RequestError after 3 attempts", err)
+	}
+	if reqErr.Unwrap() == nil {
+		t.Error("RequestError does not wrap the transport error")
+	}
+}
+
+func TestDoThrottleAndRetryCountedApart(t *testing.T) {
+	// a rate limit rejection does not use up the retries for server errors
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1, 3:
+			http.Error(w, "403 Forbidden (Rate Limit Exceeded)", http.StatusForbidden)
+		case 2:
+			http.Error(w, "down", http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	c := fastClient(srv)
+	c.Retry.MaxRetries = 1
+	c.Throttle.MaxRetries = 2
+	if _, err := c.GetCourse("1"); err != nil {
+		t.Fatalf("GetCourse: %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("%d requests, want 4", calls)
+	}
+}
+
+func TestRequestErrorMessage(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     string
+	}{
+		{1, "canvas: GET https://x/api/v1/courses/1: connection refused"},
+		{4, "canvas: GET https://x/api/v1/courses/1: connection refused (after 4 attempts)"},
+	}
+	for _, tt := range tests {
+		err := &RequestError{Method: "GET", URL: "https://x/api/v1/courses/1", Attempts: tt.attempts, Err: errors.New("connection refused")}
+		if got := err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
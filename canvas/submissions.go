@@ -0,0 +1,60 @@
+package canvas
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// Submission represents a submission to an assignment in Canvas
+type Submission struct {
+	AssignmentID                  int          `json:"assignment_id"`
+	Attempt                       int          `json:"attempt"`
+	Body                          string       `json:"body"`
+	Grade                         string       `json:"grade"`
+	GradeMatchesCurrentSubmission bool         `json:"grade_matches_current_submission"`
+	HTMLURL                       string       `json:"html_url"`
+	PreviewURL                    string       `json:"preview_url"`
+	Score                         float32      `json:"score"`
+	SubmissionType                string       `json:"submission_type"`
+	SubmittedAt                   string       `json:"submitted_at"`
+	URL                           string       `json:"url"`
+	UserID                        int          `json:"user_id"`
+	GraderID                      int          `json:"grader_id"`
+	Late                          bool         `json:"late"`
+	Excused                       bool         `json:"excused"`
+	WorkflowState                 string       `json:"workflow_state"`
+	Attachments                   []Attachment `json:"attachments"`
+	// SubmissionHistory holds every attempt, oldest first, when requested
+	// with include[]=submission_history
+	SubmissionHistory []Submission `json:"submission_history"`
+}
+
+// Attachment is a file uploaded with a submission
+type Attachment struct {
+	ID          int    `json:"id"`
+	FileName    string `json:"filename"`
+	URL         string `json:"url"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content-type"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// ListSubmissions returns every submission to an assignment, with the
+// associations named in include (e.g. "submission_history"). On error the
+// submissions fetched so far are returned as well.
+func (c *Client) ListSubmissions(courseID, assignmentID string, include ...string) ([]Submission, error) {
+	query := url.Values{}
+	for _, association := range include {
+		query.Add("include[]", association)
+	}
+	var submissions []Submission
+	err := c.getAllPages("courses/"+courseID+"/assignments/"+assignmentID+"/submissions", query, func(body []byte) error {
+		var page []Submission
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		submissions = append(submissions, page...)
+		return nil
+	})
+	return submissions, err
+}
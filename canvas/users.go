@@ -0,0 +1,17 @@
+package canvas
+
+// User represents a Canvas user
+type User struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	LoginID string `json:"login_id"`
+}
+
+// GetSelf returns the user the token belongs to.
+func (c *Client) GetSelf() (*User, error) {
+	var user User
+	if err := c.getJSON("users/self", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,122 @@
+// Package config loads the canvas-utils configuration file, which holds named
+// profiles of Canvas connection settings, one per institution:
+//
+//	default: acme
+//	profiles:
+//	  acme:
+//	    url: https://acmecollege.instructure.com/api/v1/
+//	    token: 9000~aXXXXXXXXXXXXXXXXXXX
+//	  other:
+//	    url: https://other.instructure.com/api/v1/
+//	    client_id: "170000000000042"
+//	    client_secret: XXXXXXXXXXXXXXXX
+//
+// Settings are resolved with the precedence command line flag, then
+// environment variable (CANVAS_URL, CANVAS_TOKEN), then profile.
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variables read by Resolve
+const (
+	EnvURL     = "CANVAS_URL"
+	EnvToken   = "CANVAS_TOKEN"
+	EnvProfile = "CANVAS_PROFILE"
+	EnvConfig  = "CANVAS_UTILS_CONFIG"
+)
+
+// Profile holds the connection settings for one Canvas instance.
+type Profile struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+	// ClientID and ClientSecret identify a Canvas developer key used by
+	// "canvas-utils oauth authorize"
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+}
+
+// File is the content of a configuration file.
+type File struct {
+	// Default names the profile used when none is selected
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultPath returns the configuration file used when none is given:
+// $CANVAS_UTILS_CONFIG if set, otherwise .canvas-utils.yml in the home directory.
+func DefaultPath() string {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".canvas-utils.yml"
+	}
+	return filepath.Join(home, ".canvas-utils.yml")
+}
+
+// Load reads the configuration file at path. A missing file is not an error
+// and yields an empty File.
+func Load(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &File{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	return &f, nil
+}
+
+// Exposed reports whether the file at path can be read by other users,
+// which matters once it holds tokens.
+func Exposed(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0
+}
+
+// Profile returns the named profile, or the default profile if name is empty.
+// With no name and no default an empty Profile is returned.
+func (f *File) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = f.Default
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	profile, ok := f.Profiles[name]
+	if !ok {
+		var names []string
+		for n := range f.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, errors.New("no profile named \"" + name + "\" (available: " + strings.Join(names, ", ") + ")")
+	}
+	return profile, nil
+}
+
+// Resolve picks a setting by precedence: the flag value if the flag was set
+// on the command line, then the environment variable, then the profile value.
+func Resolve(flagValue string, flagSet bool, env string, profileValue string) string {
+	if flagSet {
+		return flagValue
+	}
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	return profileValue
+}
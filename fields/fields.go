@@ -0,0 +1,74 @@
+// Package fields turns a Canvas object into a flat set of named fields, so
+// commands can print or test any field the canvas package decodes. Field
+// names are the object's JSON names, with nested objects joined by dots:
+//
+//	name, due_at, submission_types, external_tool_tag_attributes.url
+package fields
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten returns the fields of v, which must encode to a JSON object.
+// Values are JSON values: nil, bool, float64, string or []interface{}.
+func Flatten(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	flat := map[string]interface{}{}
+	flatten(flat, "", object)
+	return flat, nil
+}
+
+func flatten(flat map[string]interface{}, prefix string, object map[string]interface{}) {
+	for name, value := range object {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(flat, prefix+name+".", nested)
+			continue
+		}
+		flat[prefix+name] = value
+	}
+}
+
+// Names returns the sorted field names of v.
+func Names(v interface{}) []string {
+	flat, _ := Flatten(v)
+	names := make([]string, 0, len(flat))
+	for name := range flat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Format renders a field value for a CSV cell: null is empty, numbers have
+// no exponent and lists are joined with commas.
+func Format(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = Format(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+}
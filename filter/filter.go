@@ -0,0 +1,366 @@
+// Package filter implements the small expression language used to select
+// Canvas objects by their fields, e.g.
+//
+//	published && due_at > "2026-09-01" && "online_upload" in submission_types && !vericite_enabled
+//
+// Identifiers name fields as produced by the fields package. Literals are
+// double-quoted strings, numbers, true, false, null and lists such as
+// ["points", "percent"]. Operators, loosest binding first:
+//
+//	||                      either side is true
+//	&&                      both sides are true
+//	!                       negation
+//	== != < <= > >=         comparison; numbers compare numerically, strings
+//	                        lexically, so ISO dates compare by time
+//	in                      membership in a list, or substring of a string
+//	=~                      match against a regular expression
+//
+// A field used on its own is true unless it is false, null, 0, "" or an
+// empty list. A comparison involving null, or values of different types, is
+// false except for !=.
+package filter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Filter is a parsed expression.
+type Filter struct {
+	src    string
+	root   node
+	fields []string
+}
+
+// Parse compiles an expression.
+func Parse(src string) (*Filter, error) {
+	tokens, err := scan(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected "+tok.text)
+	}
+	return &Filter{src: src, root: root, fields: p.fields}, nil
+}
+
+// String returns the source of the expression.
+func (f *Filter) String() string {
+	return f.src
+}
+
+// Fields returns the field names the expression refers to, so callers can
+// reject unknown ones before evaluating anything.
+func (f *Filter) Fields() []string {
+	return f.fields
+}
+
+// Match evaluates the expression against an object's fields.
+func (f *Filter) Match(fields map[string]interface{}) bool {
+	return truthy(f.root.eval(fields))
+}
+
+// node is a parsed sub-expression
+type node interface {
+	eval(fields map[string]interface{}) interface{}
+}
+
+type literal struct{ value interface{} }
+
+func (n literal) eval(map[string]interface{}) interface{} { return n.value }
+
+type field struct{ name string }
+
+func (n field) eval(fields map[string]interface{}) interface{} { return fields[n.name] }
+
+type list struct{ items []node }
+
+func (n list) eval(fields map[string]interface{}) interface{} {
+	values := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		values[i] = item.eval(fields)
+	}
+	return values
+}
+
+type not struct{ x node }
+
+func (n not) eval(fields map[string]interface{}) interface{} { return !truthy(n.x.eval(fields)) }
+
+type binary struct {
+	op   string
+	x, y node
+	re   *regexp.Regexp
+}
+
+func (n binary) eval(fields map[string]interface{}) interface{} {
+	switch n.op {
+	case "&&":
+		return truthy(n.x.eval(fields)) && truthy(n.y.eval(fields))
+	case "||":
+		return truthy(n.x.eval(fields)) || truthy(n.y.eval(fields))
+	}
+	x, y := n.x.eval(fields), n.y.eval(fields)
+	switch n.op {
+	case "==":
+		return equal(x, y)
+	case "!=":
+		return !equal(x, y)
+	case "in":
+		return contains(y, x)
+	case "=~":
+		s, ok := x.(string)
+		return ok && n.re.MatchString(s)
+	}
+	c, ok := compare(x, y)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func equal(x, y interface{}) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	if xs, ok := x.([]interface{}); ok {
+		ys, ok := y.([]interface{})
+		if !ok || len(xs) != len(ys) {
+			return false
+		}
+		for i := range xs {
+			if !equal(xs[i], ys[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if _, ok := y.([]interface{}); ok {
+		return false
+	}
+	return x == y
+}
+
+func compare(x, y interface{}) (int, bool) {
+	switch x := x.(type) {
+	case float64:
+		if y, ok := y.(float64); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func contains(container, item interface{}) bool {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, v := range c {
+			if equal(v, item) {
+				return true
+			}
+		}
+	case string:
+		s, ok := item.(string)
+		return ok && strings.Contains(c, s)
+	}
+	return false
+}
+
+// parser is a recursive descent parser over the scanned tokens
+type parser struct {
+	tokens []token
+	pos    int
+	fields []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, msg string) error {
+	return &SyntaxError{Msg: msg, Pos: tok.pos + 1}
+}
+
+func (p *parser) parseOr() (node, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("||") {
+		p.next()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = binary{op: "||", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("&&") {
+		p.next()
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = binary{op: "&&", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().is("!") {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return not{x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	x, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp || !comparisons[tok.text] {
+		return x, nil
+	}
+	p.next()
+	y, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	n := binary{op: tok.text, x: x, y: y}
+	if tok.text == "=~" {
+		pattern, ok := y.(literal)
+		s, isString := pattern.value.(string)
+		if !ok || !isString {
+			return nil, p.errorf(tok, "=~ needs a string literal pattern")
+		}
+		if n.re, err = regexp.Compile(s); err != nil {
+			return nil, p.errorf(tok, "bad pattern: "+err.Error())
+		}
+	}
+	return n, nil
+}
+
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "in": true, "=~": true}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return literal{tok.value}, nil
+	case tokNumber:
+		return literal{tok.value}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "null":
+			return literal{nil}, nil
+		}
+		p.fields = append(p.fields, tok.text)
+		return field{tok.text}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if closing := p.next(); !closing.is(")") {
+				return nil, p.errorf(closing, "missing )")
+			}
+			return x, nil
+		case "[":
+			var l list
+			if p.peek().is("]") {
+				p.next()
+				return l, nil
+			}
+			for {
+				item, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				l.items = append(l.items, item)
+				sep := p.next()
+				if sep.is("]") {
+					return l, nil
+				}
+				if !sep.is(",") {
+					return nil, p.errorf(sep, "expected , or ] in list")
+				}
+			}
+		}
+	case tokEOF:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	}
+	return nil, p.errorf(tok, "unexpected "+tok.text)
+}
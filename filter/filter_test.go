@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+// assignment is a flattened assignment as the fields package produces it
+var assignment = map[string]interface{}{
+	"name":                             "Essay 1",
+	"published":                        true,
+	"vericite_enabled":                 false,
+	"due_at":                           nil,
+	"points_possible":                  10.0,
+	"grading_type":                     "points",
+	"submission_types":                 []interface{}{"online_upload", "online_text_entry"},
+	"tags":                             []interface{}{},
+	"external_tool_tag_attributes.url": "https://app.vericite.com/lti",
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// precedence: ! binds tighter than &&, which binds tighter than ||
+		{`published || vericite_enabled && false`, true},
+		{`(published || vericite_enabled) && false`, false},
+		{`!vericite_enabled && published`, true},
+		{`!(vericite_enabled || published)`, false},
+		{`!!published`, true},
+		{`vericite_enabled || vericite_enabled && published || points_possible > 5`, true},
+
+		// in: membership of a list, substring of a string
+		{`"online_upload" in submission_types`, true},
+		{`"online_url" in submission_types`, false},
+		{`grading_type in ["points", "percent"]`, true},
+		{`grading_type in ["pass_fail"]`, false},
+		{`"say" in name`, true},
+		{`"essay" in name`, false},
+		{`10 in name`, false},
+		{`"x" in tags`, false},
+		{`"x" in points_possible`, false},
+
+		// null fields
+		{`due_at`, false},
+		{`due_at == null`, true},
+		{`due_at != null`, false},
+		{`due_at > "2026-09-01"`, false},
+		{`due_at <= "2026-09-01"`, false},
+		{`due_at != "2026-09-01"`, true},
+		{`missing_field == null`, true},
+
+		// mixed types never compare
+		{`points_possible == "10"`, false},
+		{`points_possible != "10"`, true},
+		{`points_possible > "1"`, false},
+		{`name < 1`, false},
+		{`published == 1`, false},
+		{`submission_types == "online_upload"`, false},
+
+		// same types
+		{`points_possible == 10`, true},
+		{`points_possible >= 10.0 && points_possible < 10.5`, true},
+		{`name > "Essay"`, true},
+		{`submission_types == ["online_upload", "online_text_entry"]`, true},
+		{`submission_types == ["online_text_entry", "online_upload"]`, false},
+
+		// truthiness of fields on their own
+		{`tags`, false},
+		{`submission_types`, true},
+		{`points_possible`, true},
+		{`name`, true},
+
+		// regular expressions
+		{`name =~ "(?i)^essay"`, true},
+		{`external_tool_tag_attributes.url =~ "vericite\\.com"`, true},
+		{`points_possible =~ "10"`, false},
+		{`due_at =~ ".*"`, false},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := f.Match(assignment); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	f, err := Parse(`published && "x" in submission_types || name =~ "a" && null == due_at`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(f.Fields(), ",")
+	if want := "published,submission_types,name,due_at"; got != want {
+		t.Errorf("Fields() = %s, want %s", got, want)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		expr string
+		msg  string
+		pos  int
+	}{
+		{`published &&`, "unexpected end of expression", 13},
+		{``, "unexpected end of expression", 1},
+		{`name = "x"`, "unexpected character '='", 6},
+		{`(published`, "missing )", 11},
+		{`published)`, "unexpected )", 10},
+		{`name == "x`, "unterminated string", 9},
+		{`grading_type in ["points" "percent"]`, "expected , or ] in list", 27},
+		{`published published`, "unexpected published", 11},
+		{`name =~ "("`, "bad pattern: ", 6},
+		{`name =~ "[a-"`, "bad pattern: ", 6},
+		{`name =~ other`, "=~ needs a string literal pattern", 6},
+		{`name =~ 1`, "=~ needs a string literal pattern", 6},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want a *SyntaxError", tt.expr, err)
+			continue
+		}
+		if !strings.HasPrefix(syntaxErr.Msg, tt.msg) || syntaxErr.Pos != tt.pos {
+			t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.expr, syntaxErr.Msg, syntaxErr.Pos, tt.msg, tt.pos)
+		}
+	}
+}
+
+func TestSyntaxErrorMessage(t *testing.T) {
+	_, err := Parse(`published &&`)
+	if err == nil {
+		t.Fatal("Parse succeeded")
+	}
+	if got, want := err.Error(), "filter: unexpected end of expression at position 13"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func (t token) is(op string) bool {
+	return t.kind == tokOp && t.text == op
+}
+
+// operators, longest first so "<=" is not read as "<"
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "(", ")", "[", "]", ","}
+
+// scan splits an expression into tokens, ending with a tokEOF
+func scan(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, scanError("unterminated string", i)
+			}
+			s, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, scanError("bad string", i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[i : end+1], value: s, pos: i})
+			i = end + 1
+		case isDigit(c) || (c == '-' && i+1 < len(src) && isDigit(src[i+1])):
+			end := i + 1
+			for end < len(src) && (isDigit(src[end]) || src[end] == '.') {
+				end++
+			}
+			n, err := strconv.ParseFloat(src[i:end], 64)
+			if err != nil {
+				return nil, scanError("bad number", i)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:end], value: n, pos: i})
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(src) && (isIdentStart(src[end]) || isDigit(src[end]) || src[end] == '.') {
+				end++
+			}
+			text := src[i:end]
+			if text == "in" {
+				tokens = append(tokens, token{kind: tokOp, text: text, pos: i})
+			} else {
+				tokens = append(tokens, token{kind: tokIdent, text: text, pos: i})
+			}
+			i = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, scanError("unexpected character "+strconv.QuoteRune(rune(c)), i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(src)}), nil
+}
+
+func scanError(msg string, pos int) error {
+	return &SyntaxError{Msg: msg, Pos: pos + 1}
+}
+
+// SyntaxError reports a malformed expression and the 1-based position of the problem
+type SyntaxError struct {
+	Msg string
+	Pos int
+}
+
+func (e *SyntaxError) Error() string {
+	return "filter: " + e.Msg + " at position " + strconv.Itoa(e.Pos)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,15 @@
+module github.com/vericite/canvas-utils
+
+go 1.25.0
+
+require (
+	github.com/alexcesaro/log v0.0.0-20150915221235-61e686294e58
+	golang.org/x/crypto v0.54.0
+	golang.org/x/net v0.57.0
+	golang.org/x/oauth2 v0.36.0
+	golang.org/x/term v0.45.0
+	golang.org/x/text v0.40.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require golang.org/x/sys v0.47.0 // indirect
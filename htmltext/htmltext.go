@@ -0,0 +1,81 @@
+// Package htmltext turns the HTML Canvas stores for text entry submissions
+// into plain text: markup, scripts and styles are dropped, block elements
+// and line breaks become new lines and runs of white space are collapsed.
+package htmltext
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blocks are the elements that start a new line
+var blocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "figure": true,
+	"footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "tr": true, "ul": true,
+}
+
+// paragraphs are the blocks set apart by a blank line
+var paragraphs = map[string]bool{
+	"blockquote": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "ol": true, "p": true, "pre": true, "table": true, "ul": true,
+}
+
+// skipped are the elements whose content is not text
+var skipped = map[string]bool{"script": true, "style": true, "head": true, "title": true, "noscript": true, "template": true}
+
+// Text returns the plain text of an HTML fragment or document.
+func Text(src string) string {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var out strings.Builder
+	var line strings.Builder
+	blank := false
+	skip := 0
+	flush := func() {
+		text := strings.Join(strings.Fields(line.String()), " ")
+		line.Reset()
+		if text == "" {
+			return
+		}
+		if blank && out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		blank = false
+		out.WriteString(text + "\n")
+	}
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			// io.EOF, or malformed input; what was read so far is kept
+			flush()
+			return out.String()
+		case html.TextToken:
+			if skip == 0 {
+				line.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skipped[tag] && tt != html.SelfClosingTagToken {
+				if tt == html.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if blocks[tag] {
+				flush()
+				if paragraphs[tag] {
+					blank = true
+				}
+			} else if tag == "td" || tag == "th" {
+				line.WriteString(" ")
+			}
+		}
+	}
+}
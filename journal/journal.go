@@ -0,0 +1,128 @@
+// Package journal records which (courseId, assignmentId) rows of an input CSV
+// a command has already processed, so an interrupted run can be resumed
+// without redoing the rows that succeeded.
+package journal
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded for a row
+const (
+	Done   = "done"
+	Failed = "failed"
+)
+
+// Journal is an append-only CSV file with one line per processed row:
+//
+//	timestamp,courseId,assignmentId,outcome,message
+//
+// Every line is flushed as soon as it is recorded so the journal survives the
+// process being killed. A Journal is safe for concurrent use.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *csv.Writer
+	done map[string]bool
+}
+
+// Open opens the journal at path. With resume the rows already recorded are
+// loaded and new rows are appended; otherwise the journal is started afresh.
+func Open(path string, resume bool) (*Journal, error) {
+	j := &Journal{done: map[string]bool{}}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := j.load(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j.file = file
+	j.w = csv.NewWriter(file)
+	if resume {
+		terminate(path, file)
+	}
+	return j, nil
+}
+
+// load reads an existing journal; the last outcome recorded for a row wins.
+func (j *Journal) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			// a line cut short by a crash; everything before it is usable
+			if _, ok := err.(*csv.ParseError); ok {
+				return nil
+			}
+			return err
+		}
+		if len(record) < 4 {
+			continue
+		}
+		j.done[key(record[1], record[2])] = record[3] == Done
+	}
+}
+
+// terminate ends a line left unfinished by a crash so appended rows start on
+// a line of their own.
+func terminate(path string, file *os.File) {
+	r, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	info, err := r.Stat()
+	if err != nil || info.Size() == 0 {
+		return
+	}
+	last := make([]byte, 1)
+	if _, err := r.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+		file.Write([]byte("\n"))
+	}
+}
+
+// Done reports whether a row was recorded as done in the journal being resumed.
+func (j *Journal) Done(courseID, assignmentID string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done[key(courseID, assignmentID)]
+}
+
+// Record appends the outcome of a row to the journal.
+func (j *Journal) Record(courseID, assignmentID, outcome, message string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done[key(courseID, assignmentID)] = outcome == Done
+	j.w.Write([]string{time.Now().UTC().Format(time.RFC3339), courseID, assignmentID, outcome, message})
+	j.w.Flush()
+	return j.w.Error()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.w.Flush()
+	return j.file.Close()
+}
+
+func key(courseID, assignmentID string) string {
+	return courseID + ":" + assignmentID
+}
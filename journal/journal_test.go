@@ -0,0 +1,151 @@
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		desc    string
+		journal string
+		done    map[string]bool
+	}{
+		{
+			"done and failed rows",
+			"2026-10-01T10:00:00Z,1,5,done,\n2026-10-01T10:00:01Z,1,6,failed,404 Not Found\n",
+			map[string]bool{"1:5": true, "1:6": false, "1:7": false},
+		},
+		{
+			"last outcome wins",
+			"2026-10-01T10:00:00Z,1,5,failed,timeout\n2026-10-01T10:00:01Z,1,6,done,\n" +
+				"2026-10-01T11:00:00Z,1,5,done,\n2026-10-01T11:00:01Z,1,6,failed,500 Internal Server Error\n",
+			map[string]bool{"1:5": true, "1:6": false},
+		},
+		{
+			"ids are not confused across courses",
+			"2026-10-01T10:00:00Z,1,5,done,\n",
+			map[string]bool{"1:5": true, "5:1": false, "15:": false},
+		},
+		{
+			"line cut short by a crash",
+			"2026-10-01T10:00:00Z,1,5,done,\n2026-10-01T10:00:01Z,1,6,do",
+			map[string]bool{"1:5": true, "1:6": false},
+		},
+		{
+			"line cut short before the outcome",
+			"2026-10-01T10:00:00Z,1,5,done,\n2026-10-01T10:00:01Z,1,6",
+			map[string]bool{"1:5": true, "1:6": false},
+		},
+		{
+			"unterminated quoted message",
+			"2026-10-01T10:00:00Z,1,5,done,\n2026-10-01T10:00:01Z,1,6,failed,\"canvas: 500, retr",
+			map[string]bool{"1:5": true, "1:6": false},
+		},
+		{
+			"quoted message with a comma and a newline",
+			"2026-10-01T10:00:00Z,1,5,failed,\"a, b\nc\"\n2026-10-01T10:00:01Z,1,6,done,\n",
+			map[string]bool{"1:5": false, "1:6": true},
+		},
+		{"empty journal", "", map[string]bool{"1:5": false}},
+	}
+	for _, tt := range tests {
+		path := writeJournal(t, tt.journal)
+		j, err := Open(path, true)
+		if err != nil {
+			t.Errorf("%s: Open: %v", tt.desc, err)
+			continue
+		}
+		for k, want := range tt.done {
+			ids := strings.SplitN(k, ":", 2)
+			if got := j.Done(ids[0], ids[1]); got != want {
+				t.Errorf("%s: Done(%s, %s) = %v, want %v", tt.desc, ids[0], ids[1], got, want)
+			}
+		}
+		j.Close()
+	}
+}
+
+func TestResumeAppends(t *testing.T) {
+	// a row appended after a crash starts on a line of its own and the
+	// journal can be resumed again
+	path := writeJournal(t, "2026-10-01T10:00:00Z,1,5,done,\n2026-10-01T10:00:01Z,1,6,fai")
+	j, err := Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record("1", "6", Done, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !j.Done("1", "6") {
+		t.Error("Done(1, 6) = false after recording it")
+	}
+	j.Close()
+
+	lines := strings.Split(strings.TrimSuffix(readJournal(t, path), "\n"), "\n")
+	if len(lines) != 3 || lines[1] != "2026-10-01T10:00:01Z,1,6,fai" || !strings.HasSuffix(lines[2], ",1,6,done,") {
+		t.Fatalf("journal lines = %q", lines)
+	}
+	j, err = Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if !j.Done("1", "5") || !j.Done("1", "6") {
+		t.Errorf("Done after resuming again = %v, %v, want true, true", j.Done("1", "5"), j.Done("1", "6"))
+	}
+}
+
+func TestResumeMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.csv")
+	j, err := Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if j.Done("1", "5") {
+		t.Error("Done(1, 5) = true in a new journal")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("journal not created: %v", err)
+	}
+}
+
+func TestOpenStartsAfresh(t *testing.T) {
+	path := writeJournal(t, "2026-10-01T10:00:00Z,1,5,done,\n")
+	j, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Done("1", "5") {
+		t.Error("Done(1, 5) = true without resume")
+	}
+	if err := j.Record("1", "6", Failed, `canvas: 404 "not found"`); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	got := readJournal(t, path)
+	if !strings.HasSuffix(got, `,1,6,failed,"canvas: 404 ""not found"""`+"\n") || strings.Count(got, "\n") != 1 {
+		t.Errorf("journal = %q, want only the new row", got)
+	}
+}
+
+// writeJournal writes contents to a journal file in a new directory
+func writeJournal(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "journal.csv")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readJournal(t *testing.T, path string) string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
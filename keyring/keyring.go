@@ -0,0 +1,172 @@
+// Package keyring keeps Canvas tokens per Canvas instance in a local file
+// encrypted with a passphrase. The key is derived from the passphrase with
+// scrypt and the tokens are sealed with AES-256-GCM, so the file can be
+// carried between operating systems without depending on a system keychain.
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/vericite/canvas-utils/atomicfile"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Environment variables read by canvas-utils
+const (
+	// EnvPath overrides the location of the keyring file
+	EnvPath = "CANVAS_KEYRING"
+	// EnvPassphrase supplies the passphrase for unattended runs
+	EnvPassphrase = "CANVAS_KEYRING_PASSPHRASE"
+)
+
+// ErrPassphrase is returned when the keyring can not be decrypted
+var ErrPassphrase = errors.New("keyring: wrong passphrase or corrupted file")
+
+// scrypt parameters recommended for interactive logins
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+	saltLen = 16
+)
+
+// file is the on-disk format; only Data is secret
+type file struct {
+	Version int    `json:"version"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Keyring is the decrypted content of a keyring file.
+type Keyring struct {
+	path       string
+	passphrase string
+	tokens     map[string]string
+}
+
+// DefaultPath returns $CANVAS_KEYRING if set, otherwise .canvas-utils-keyring
+// in the home directory.
+func DefaultPath() string {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".canvas-utils-keyring"
+	}
+	return filepath.Join(home, ".canvas-utils-keyring")
+}
+
+// Exists reports whether there is a keyring file at path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open decrypts the keyring at path. A missing file gives an empty keyring
+// that is created with passphrase on the first Save.
+func Open(path, passphrase string) (*Keyring, error) {
+	k := &Keyring{path: path, passphrase: passphrase, tokens: map[string]string{}}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return k, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errors.New("keyring: " + path + " is not a keyring file: " + err.Error())
+	}
+	if f.Version != 1 {
+		return nil, errors.New("keyring: unsupported keyring version in " + path)
+	}
+	gcm, err := newGCM(passphrase, f.Salt, f.N, f.R, f.P)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	if err := json.Unmarshal(plain, &k.tokens); err != nil {
+		return nil, ErrPassphrase
+	}
+	return k, nil
+}
+
+func newGCM(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Get returns the token stored for a Canvas instance, or "" if there is none.
+func (k *Keyring) Get(root string) string {
+	return k.tokens[root]
+}
+
+// Set stores the token for a Canvas instance; call Save to write it.
+func (k *Keyring) Set(root, token string) {
+	k.tokens[root] = token
+}
+
+// Delete removes the token for a Canvas instance; call Save to write it.
+func (k *Keyring) Delete(root string) {
+	delete(k.tokens, root)
+}
+
+// Roots returns the Canvas instances holding a token, sorted.
+func (k *Keyring) Roots() []string {
+	var roots []string
+	for root := range k.tokens {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// Save encrypts the keyring with a fresh salt and nonce and replaces the
+// file atomically, readable only by its owner.
+func (k *Keyring) Save() error {
+	plain, err := json.Marshal(k.tokens)
+	if err != nil {
+		return err
+	}
+	f := file{Version: 1, N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, saltLen)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(k.passphrase, f.Salt, f.N, f.R, f.P)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, nil)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(k.path, data, 0600)
+}
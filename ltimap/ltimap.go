@@ -0,0 +1,137 @@
+// Package ltimap maps old external tool (LTI) launch URLs to new ones for
+// migrations. A mapping is a CSV file with the columns
+//
+//	pattern,target,courseId,accountId
+//
+// pattern is a regular expression matched against an assignment's
+// external_tool_tag_attributes url, and target is the URL that replaces it;
+// target may refer to submatches of pattern as $1 or ${name}. The optional
+// courseId and accountId columns restrict a rule to one course or to the
+// courses of one account. accountId is compared with the account_id of the
+// course, the account it belongs to directly, so a rule for a parent account
+// does not cover the courses of its sub-accounts; give each sub-account its
+// own rule. Rules are tried in file order and the first matching rule wins.
+// A header line starting with "pattern" is skipped.
+package ltimap
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// VeriCiteURL is the VeriCite LTI launch URL assignments are migrated to by default
+const VeriCiteURL = "https://api.vericite.com/web/v1/authenticate/lti"
+
+// Rule maps URLs matching Pattern to Target.
+type Rule struct {
+	Pattern   *regexp.Regexp
+	Target    string
+	CourseID  string
+	AccountID string
+}
+
+// Map is an ordered list of Rules.
+type Map struct {
+	Rules []Rule
+}
+
+// Default returns the built-in mapping from the old VeriCite LTI hosts to VeriCiteURL.
+func Default() *Map {
+	return &Map{Rules: []Rule{{
+		Pattern: regexp.MustCompile(`longsight\.com|app\.vericite\.com`),
+		Target:  VeriCiteURL,
+	}}}
+}
+
+// Load reads a mapping file.
+func Load(path string) (*Map, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	m := &Map{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if line == 1 && record[0] == "pattern" {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, errors.New(path + ":" + strconv.Itoa(line) + ": expected at least pattern and target")
+		}
+		pattern, err := regexp.Compile(record[0])
+		if err != nil {
+			return nil, errors.New(path + ":" + strconv.Itoa(line) + ": " + err.Error())
+		}
+		rule := Rule{Pattern: pattern, Target: record[1]}
+		if len(record) > 2 {
+			rule.CourseID = record[2]
+		}
+		if len(record) > 3 {
+			rule.AccountID = record[3]
+		}
+		m.Rules = append(m.Rules, rule)
+	}
+	if len(m.Rules) == 0 {
+		return nil, errors.New(path + ": no mapping rules")
+	}
+	return m, nil
+}
+
+// LoadOrDefault loads the mapping file at path, or returns Default if path is empty.
+func LoadOrDefault(path string) (*Map, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	return Load(path)
+}
+
+// NeedsAccount reports whether any rule is restricted to an account, in
+// which case callers must pass the course's account id to Rewrite.
+func (m *Map) NeedsAccount() bool {
+	for _, rule := range m.Rules {
+		if rule.AccountID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Rewrite returns the new URL for oldURL in the given course and account,
+// and whether a rule matched. accountID is the account the course belongs to
+// directly; parent accounts are not consulted. A URL that a rule maps onto itself is reported
+// as not matching, since there is nothing to migrate.
+func (m *Map) Rewrite(oldURL, courseID, accountID string) (string, bool) {
+	if oldURL == "" {
+		return "", false
+	}
+	for _, rule := range m.Rules {
+		if rule.CourseID != "" && rule.CourseID != courseID {
+			continue
+		}
+		if rule.AccountID != "" && rule.AccountID != accountID {
+			continue
+		}
+		match := rule.Pattern.FindStringSubmatchIndex(oldURL)
+		if match == nil {
+			continue
+		}
+		newURL := string(rule.Pattern.ExpandString(nil, rule.Target, oldURL, match))
+		if newURL == oldURL {
+			return "", false
+		}
+		return newURL, true
+	}
+	return "", false
+}
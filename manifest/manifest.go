@@ -0,0 +1,206 @@
+// Package manifest keeps an index of the files an export wrote, as a CSV file
+// at the root of the export folder:
+//
+//	path,courseId,assignmentId,userId,attempt,fileId,originalName,size,contentType,sha256,updatedAt
+//
+// path is relative to the export folder. originalName is the file name as
+// Canvas gave it, before it was made safe to write to disk. sha256 is the
+// hex SHA-256 checksum of the file's content. updatedAt is the version of
+// the file in Canvas: the attachment's updated_at, or the submission's
+// submitted_at for text entries. Entries are appended as files
+// are written, so a later line for the same path replaces an earlier one.
+package manifest
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vericite/canvas-utils/atomicfile"
+)
+
+// FileName is the name of the manifest inside the export folder
+const FileName = "manifest.csv"
+
+// columns of the manifest, in order
+var columns = []string{"path", "courseId", "assignmentId", "userId", "attempt", "fileId", "originalName", "size", "contentType", "sha256", "updatedAt"}
+
+// Entry describes one exported file.
+type Entry struct {
+	Path         string
+	CourseID     string
+	AssignmentID string
+	UserID       string
+	Attempt      string
+	FileID       string
+	OriginalName string
+	Size         int64
+	ContentType  string
+	SHA256       string
+	UpdatedAt    string
+}
+
+func (e Entry) record() []string {
+	return []string{e.Path, e.CourseID, e.AssignmentID, e.UserID, e.Attempt, e.FileID, e.OriginalName, strconv.FormatInt(e.Size, 10), e.ContentType, e.SHA256, e.UpdatedAt}
+}
+
+func fromRecord(index map[string]int, record []string) Entry {
+	get := func(name string) string {
+		if i, ok := index[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	size, _ := strconv.ParseInt(get("size"), 10, 64)
+	return Entry{
+		Path:         get("path"),
+		CourseID:     get("courseId"),
+		AssignmentID: get("assignmentId"),
+		UserID:       get("userId"),
+		Attempt:      get("attempt"),
+		FileID:       get("fileId"),
+		OriginalName: get("originalName"),
+		Size:         size,
+		ContentType:  get("contentType"),
+		SHA256:       get("sha256"),
+		UpdatedAt:    get("updatedAt"),
+	}
+}
+
+// Manifest is the manifest of one export folder. A Manifest is safe for
+// concurrent use.
+type Manifest struct {
+	mu      sync.Mutex
+	dir     string
+	file    *os.File
+	w       *csv.Writer
+	entries map[string]Entry
+	// order lists the paths in the order they were first recorded
+	order []string
+}
+
+// Open opens the manifest of the export folder dir, loading the entries of
+// earlier runs and creating the folder and manifest if needed.
+func Open(dir string) (*Manifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	m := &Manifest{dir: dir, entries: map[string]Entry{}}
+	path := filepath.Join(dir, FileName)
+	header, err := m.load(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if header != nil && strings.Join(header, ",") != strings.Join(columns, ",") {
+		// written by an older version; rewrite it with the current columns
+		if err := m.rewrite(path); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	m.file = file
+	m.w = csv.NewWriter(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		m.w.Write(columns)
+		m.w.Flush()
+	}
+	return m, nil
+}
+
+// load reads an existing manifest, locating the columns by the header, and
+// returns the header
+func (m *Manifest) load(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	var header []string
+	index := map[string]int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return header, nil
+		} else if err != nil {
+			// a line cut short by a crash; everything before it is usable
+			if _, ok := err.(*csv.ParseError); ok {
+				return header, nil
+			}
+			return nil, err
+		}
+		if header == nil {
+			header = record
+			for i, name := range record {
+				index[name] = i
+			}
+			continue
+		}
+		m.set(fromRecord(index, record))
+	}
+}
+
+// set makes e the latest entry for its path
+func (m *Manifest) set(e Entry) {
+	if _, ok := m.entries[e.Path]; !ok {
+		m.order = append(m.order, e.Path)
+	}
+	m.entries[e.Path] = e
+}
+
+// rewrite replaces the manifest at path with the loaded entries, one per path
+func (m *Manifest) rewrite(path string) error {
+	return atomicfile.Write(path, 0644, func(f *os.File) error {
+		w := csv.NewWriter(f)
+		w.Write(columns)
+		for _, p := range m.order {
+			w.Write(m.entries[p].record())
+		}
+		w.Flush()
+		return w.Error()
+	})
+}
+
+// Rel returns path relative to the export folder, with forward slashes, as
+// stored in the manifest.
+func (m *Manifest) Rel(path string) string {
+	rel, err := filepath.Rel(m.dir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Add records an exported file; e.Path is relative to the export folder.
+func (m *Manifest) Add(e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(e)
+	m.w.Write(e.record())
+	m.w.Flush()
+	return m.w.Error()
+}
+
+// Get returns the latest entry recorded for a path relative to the export folder.
+func (m *Manifest) Get(path string) (Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[path]
+	return e, ok
+}
+
+// Close closes the manifest file.
+func (m *Manifest) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.w.Flush()
+	return m.file.Close()
+}
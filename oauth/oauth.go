@@ -0,0 +1,195 @@
+// Package oauth authorizes canvas-utils against Canvas with an OAuth2
+// developer key, using the authorization-code flow with a local callback
+// listener, and refreshes the resulting access token when it expires.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultPort is the local port Canvas redirects to after authorization. The
+// developer key must list http://localhost:<port>/oauth/callback as a
+// redirect URI.
+const DefaultPort = 8765
+
+// CallbackPath is the path of the redirect URI
+const CallbackPath = "/oauth/callback"
+
+// Credentials are a developer key and the tokens Canvas issued for it.
+type Credentials struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Root returns the scheme and host of a Canvas API base URL such as
+// "https://acmecollege.instructure.com/api/v1/", where the OAuth2 endpoints live.
+func Root(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", errors.New("oauth: not an absolute URL: " + baseURL)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+func config(root, clientID, clientSecret, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   root + "/login/oauth2/auth",
+			TokenURL:  root + "/login/oauth2/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// Authorize runs the authorization-code flow against the Canvas instance at
+// root. It listens on localhost:port for the redirect, calls prompt with the
+// URL the user must open in a browser, and exchanges the returned code for
+// tokens. It gives up after timeout.
+func Authorize(root, clientID, clientSecret string, port int, timeout time.Duration, prompt func(authURL string)) (*Credentials, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	conf := config(root, clientID, clientSecret, "http://localhost:"+strconv.Itoa(port)+CallbackPath)
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("oauth: callback state does not match")
+		case q.Get("error") != "":
+			res.err = errors.New("oauth: authorization denied: " + q.Get("error") + " " + q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = errors.New("oauth: callback without a code")
+		default:
+			res.code = q.Get("code")
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			w.Write([]byte("canvas-utils is authorized; you can close this window."))
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	prompt(conf.AuthCodeURL(state))
+
+	var res result
+	select {
+	case res = <-results:
+	case <-time.After(timeout):
+		return nil, errors.New("oauth: no authorization received within " + timeout.String())
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	token, err := conf.Exchange(ctx, res.code)
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}, nil
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// TokenSource hands out the access token of a set of Credentials,
+// refreshing it shortly before it expires. It implements
+// canvas.TokenSource and is safe for concurrent use.
+type TokenSource struct {
+	mu    sync.Mutex
+	src   oauth2.TokenSource
+	creds Credentials
+	save  func(*Credentials) error
+}
+
+// NewTokenSource returns a TokenSource for creds issued by the Canvas
+// instance at root. save, if not nil, is called with the new credentials
+// every time the token is refreshed so later runs can reuse them.
+func NewTokenSource(root string, creds *Credentials, save func(*Credentials) error) *TokenSource {
+	conf := config(root, creds.ClientID, creds.ClientSecret, "")
+	token := &oauth2.Token{
+		AccessToken:  creds.AccessToken,
+		RefreshToken: creds.RefreshToken,
+		Expiry:       creds.Expiry,
+		TokenType:    "Bearer",
+	}
+	return &TokenSource{
+		src:   conf.TokenSource(context.Background(), token),
+		creds: *creds,
+		save:  save,
+	}
+}
+
+// AccessToken returns a valid access token, refreshing it if needed.
+func (t *TokenSource) AccessToken() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	token, err := t.src.Token()
+	if err != nil {
+		return "", err
+	}
+	if token.AccessToken != t.creds.AccessToken {
+		t.creds.AccessToken = token.AccessToken
+		t.creds.RefreshToken = token.RefreshToken
+		t.creds.Expiry = token.Expiry
+		if t.save != nil {
+			if err := t.save(&t.creds); err != nil {
+				return "", errors.New("oauth: refreshed token could not be saved: " + err.Error())
+			}
+		}
+	}
+	return token.AccessToken, nil
+}
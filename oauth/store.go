@@ -0,0 +1,80 @@
+package oauth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/vericite/canvas-utils/atomicfile"
+)
+
+// EnvStore overrides the location of the OAuth2 token store
+const EnvStore = "CANVAS_OAUTH_TOKENS"
+
+// DefaultStorePath returns $CANVAS_OAUTH_TOKENS if set, otherwise
+// .canvas-utils-oauth.json in the home directory.
+func DefaultStorePath() string {
+	if path := os.Getenv(EnvStore); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".canvas-utils-oauth.json"
+	}
+	return filepath.Join(home, ".canvas-utils-oauth.json")
+}
+
+// Store is a JSON file, readable only by its owner, holding Credentials per
+// Canvas root URL.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore returns the Store at path; the file is created on the first Put.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) read() (map[string]*Credentials, error) {
+	all := map[string]*Credentials{}
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// Get returns the Credentials stored for a Canvas root URL, or nil if there are none.
+func (s *Store) Get(root string) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return all[root], nil
+}
+
+// Put stores the Credentials for a Canvas root URL, replacing the file atomically.
+func (s *Store) Put(root string, creds *Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[root] = creds
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, data, 0600)
+}
@@ -0,0 +1,62 @@
+// Package pool fans out per-row work from the input CSV files to a bounded
+// number of goroutines while keeping the results in input order.
+package pool
+
+import "sync"
+
+// Ordered calls work(i) for every i in [0, n) on up to workers goroutines
+// and calls emit(i, result) on the calling goroutine in increasing order of
+// i, so output written by emit keeps the order of the input. Finished
+// results waiting for a slower earlier row are buffered, but never more than
+// a few per worker.
+func Ordered(workers, n int, work func(i int) interface{}, emit func(i int, result interface{})) {
+	if workers < 1 {
+		workers = 1
+	}
+	type result struct {
+		i int
+		v interface{}
+	}
+	window := make(chan struct{}, workers*4)
+	jobs := make(chan int)
+	results := make(chan result)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			window <- struct{}{}
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results <- result{i, work(i)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := map[int]interface{}{}
+	next := 0
+	for r := range results {
+		pending[r.i] = r.v
+		for {
+			v, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			emit(next, v)
+			<-window
+			next++
+		}
+	}
+}
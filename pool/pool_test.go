@@ -0,0 +1,94 @@
+package pool
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrdered(t *testing.T) {
+	tests := []struct {
+		workers int
+		n       int
+	}{
+		{1, 20},
+		{4, 100},
+		{16, 50},
+		{0, 10},
+		{-3, 5},
+		{4, 0},
+		{8, 3},
+	}
+	for _, tt := range tests {
+		var mu sync.Mutex
+		calls := make([]int, tt.n)
+		var got []int
+		Ordered(tt.workers, tt.n, func(i int) interface{} {
+			time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+			mu.Lock()
+			calls[i]++
+			mu.Unlock()
+			return i * i
+		}, func(i int, result interface{}) {
+			if result.(int) != i*i {
+				t.Errorf("workers %d: emit(%d, %v), want the result of work(%d)", tt.workers, i, result, i)
+			}
+			got = append(got, i)
+		})
+		if len(got) != tt.n {
+			t.Errorf("workers %d, n %d: %d results emitted", tt.workers, tt.n, len(got))
+		}
+		for i, g := range got {
+			if g != i {
+				t.Errorf("workers %d: result %d emitted at position %d", tt.workers, g, i)
+				break
+			}
+		}
+		for i, c := range calls {
+			if c != 1 {
+				t.Errorf("workers %d: work(%d) called %d times", tt.workers, i, c)
+			}
+		}
+	}
+}
+
+func TestOrderedBounds(t *testing.T) {
+	// the first row is slow, so every other row finishes ahead of it and
+	// waits to be emitted
+	const workers, n = 3, 60
+	var mu sync.Mutex
+	running, maxRunning, started, emitted, maxAhead := 0, 0, 0, 0, 0
+	Ordered(workers, n, func(i int) interface{} {
+		mu.Lock()
+		running++
+		started++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		if started-emitted > maxAhead {
+			maxAhead = started - emitted
+		}
+		mu.Unlock()
+		if i == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}, func(i int, result interface{}) {
+		mu.Lock()
+		emitted++
+		mu.Unlock()
+	})
+	if maxRunning > workers {
+		t.Errorf("%d rows worked on at once, want at most %d", maxRunning, workers)
+	}
+	if maxAhead > workers*4 {
+		t.Errorf("%d rows started ahead of the output, want at most %d", maxAhead, workers*4)
+	}
+	if maxAhead < workers*4 {
+		t.Errorf("%d rows started ahead of the slow first row, want %d", maxAhead, workers*4)
+	}
+}
@@ -0,0 +1,107 @@
+// Package report writes a machine-readable run report: a JSON Lines file with
+// one record per entity a command processed, so bulk changes can be audited
+// and reconciled after the run.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vericite/canvas-utils/canvas"
+)
+
+// Outcomes of a record
+const (
+	OK      = "ok"
+	Failed  = "failed"
+	Skipped = "skipped"
+	DryRun  = "dry-run"
+)
+
+// Record is one line of the report. Status is the HTTP status of Canvas's
+// last response for the entity, whether the request succeeded or failed;
+// commands fill it in through canvas.Client.WithStatus. It is 0 when Canvas
+// could not be reached at all. CanvasError is the message from Canvas's
+// error body.
+type Record struct {
+	Time         string `json:"time"`
+	Command      string `json:"command"`
+	Action       string `json:"action"`
+	AccountID    string `json:"accountId,omitempty"`
+	CourseID     string `json:"courseId,omitempty"`
+	AssignmentID string `json:"assignmentId,omitempty"`
+	UserID       string `json:"userId,omitempty"`
+	Attempt      int    `json:"attempt,omitempty"`
+	FileID       string `json:"fileId,omitempty"`
+	Outcome      string `json:"outcome"`
+	Status       int    `json:"status,omitempty"`
+	Error        string `json:"error,omitempty"`
+	CanvasError  string `json:"canvasError,omitempty"`
+	DurationMS   int64  `json:"durationMs"`
+}
+
+// Writer appends Records to a report file. Every record is written as soon
+// as it is added so the report survives the process being killed. A nil
+// *Writer discards records, so commands can report unconditionally. A Writer
+// is safe for concurrent use.
+type Writer struct {
+	mu      sync.Mutex
+	file    *os.File
+	command string
+}
+
+// Open starts a report for command at path, replacing any previous report.
+func Open(path, command string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{file: file, command: command}, nil
+}
+
+// Add completes rec with the time, the command, the duration since start and
+// the details of err, then writes it. The status of a *canvas.Error replaces
+// rec.Status. The outcome is Failed if err is not
+// nil, otherwise rec.Outcome or OK if that is empty.
+func (w *Writer) Add(rec Record, start time.Time, err error) error {
+	if w == nil {
+		return nil
+	}
+	rec.Time = time.Now().UTC().Format(time.RFC3339)
+	rec.Command = w.command
+	rec.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		rec.Error = err.Error()
+		var apiErr *canvas.Error
+		if errors.As(err, &apiErr) {
+			rec.Status = apiErr.StatusCode
+			rec.CanvasError = apiErr.Message()
+		}
+	}
+	if err != nil {
+		rec.Outcome = Failed
+	} else if rec.Outcome == "" {
+		rec.Outcome = OK
+	}
+	line, merr := json.Marshal(rec)
+	if merr != nil {
+		return merr
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, werr := w.file.Write(append(line, '\n'))
+	return werr
+}
+
+// Close closes the report file.
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
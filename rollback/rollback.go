@@ -0,0 +1,141 @@
+// Package rollback keeps a log of assignment settings as they were before a
+// bulk change, so the change can be undone later.
+package rollback
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is the state of one assignment before it was modified. Params holds
+// the prior values as assignment update parameters, e.g.
+// "assignment[vericite_enabled]": "false".
+type Entry struct {
+	Time         string            `json:"time"`
+	CourseID     string            `json:"courseId"`
+	AssignmentID string            `json:"assignmentId"`
+	Params       map[string]string `json:"params"`
+}
+
+// Values returns the entry's parameters ready for canvas.Client.UpdateAssignment.
+func (e Entry) Values() url.Values {
+	values := url.Values{}
+	for name, value := range e.Params {
+		values.Set(name, value)
+	}
+	return values
+}
+
+// Log is a JSON Lines file of Entries, appended to as assignments are
+// modified. A Log is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the rollback log at path for appending, creating it if needed.
+// Existing entries are kept so a resumed run adds to the same log.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{file: file}, nil
+}
+
+// Record appends the prior state of an assignment to the log. It must be
+// called, and succeed, before the assignment is modified.
+func (l *Log) Record(courseID, assignmentID string, prior url.Values) error {
+	entry := Entry{
+		Time:         time.Now().UTC().Format(time.RFC3339),
+		CourseID:     courseID,
+		AssignmentID: assignmentID,
+		Params:       map[string]string{},
+	}
+	for name := range prior {
+		entry.Params[name] = prior.Get(name)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	return l.file.Close()
+}
+
+// Read returns every entry of the rollback log at path, oldest first. Lines
+// that cannot be parsed, such as one cut short by a crash, are skipped and
+// reported in the returned error alongside the entries that could be read.
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []Entry
+	var bad []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			bad = append(bad, strconv.Itoa(line))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, err
+	}
+	if len(bad) > 0 {
+		return entries, errors.New("rollback: skipped unreadable lines " + strings.Join(bad, ", ") + " of " + path)
+	}
+	return entries, nil
+}
+
+// Originals reduces entries, oldest first, to one entry per assignment
+// holding the values it had before the first recorded change, in the order
+// the assignments were first modified.
+func Originals(entries []Entry) []Entry {
+	var originals []Entry
+	index := map[string]int{}
+	for _, entry := range entries {
+		key := entry.CourseID + ":" + entry.AssignmentID
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(originals)
+			params := map[string]string{}
+			for name, value := range entry.Params {
+				params[name] = value
+			}
+			entry.Params = params
+			originals = append(originals, entry)
+			continue
+		}
+		for name, value := range entry.Params {
+			if _, seen := originals[i].Params[name]; !seen {
+				originals[i].Params[name] = value
+			}
+		}
+	}
+	return originals
+}
@@ -0,0 +1,130 @@
+package rollback
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOriginals(t *testing.T) {
+	tests := []struct {
+		desc    string
+		entries []Entry
+		want    []Entry
+	}{
+		{
+			"first value of each run wins",
+			[]Entry{
+				{"t1", "1", "5", map[string]string{"assignment[vericite_enabled]": "false"}},
+				{"t2", "1", "5", map[string]string{"assignment[vericite_enabled]": "true"}},
+			},
+			[]Entry{
+				{"t1", "1", "5", map[string]string{"assignment[vericite_enabled]": "false"}},
+			},
+		},
+		{
+			"params first changed by a later run added",
+			[]Entry{
+				{"t1", "1", "5", map[string]string{"assignment[vericite_enabled]": "false"}},
+				{"t2", "1", "5", map[string]string{
+					"assignment[vericite_enabled]":                  "true",
+					"assignment[external_tool_tag_attributes][url]": "https://lti.example.com/launch",
+				}},
+				{"t3", "1", "5", map[string]string{"assignment[external_tool_tag_attributes][url]": "https://app.vericite.com/lti"}},
+			},
+			[]Entry{
+				{"t1", "1", "5", map[string]string{
+					"assignment[vericite_enabled]":                  "false",
+					"assignment[external_tool_tag_attributes][url]": "https://lti.example.com/launch",
+				}},
+			},
+		},
+		{
+			"assignments kept in the order first modified",
+			[]Entry{
+				{"t1", "2", "9", map[string]string{"assignment[name]": "Lab"}},
+				{"t2", "1", "5", map[string]string{"assignment[name]": "Essay"}},
+				{"t3", "2", "9", map[string]string{"assignment[name]": "Lab 2"}},
+				{"t4", "1", "9", map[string]string{"assignment[name]": "Quiz"}},
+			},
+			[]Entry{
+				{"t1", "2", "9", map[string]string{"assignment[name]": "Lab"}},
+				{"t2", "1", "5", map[string]string{"assignment[name]": "Essay"}},
+				{"t4", "1", "9", map[string]string{"assignment[name]": "Quiz"}},
+			},
+		},
+		{"no entries", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := Originals(tt.entries); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Originals = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestOriginalsKeepsEntries(t *testing.T) {
+	entries := []Entry{
+		{"t1", "1", "5", map[string]string{"assignment[vericite_enabled]": "false"}},
+		{"t2", "1", "5", map[string]string{"assignment[name]": "Essay"}},
+	}
+	Originals(entries)
+	if len(entries[0].Params) != 1 {
+		t.Errorf("Originals changed its input: %v", entries[0].Params)
+	}
+}
+
+func TestRecordRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollback.jsonl")
+	prior := []url.Values{
+		{"assignment[vericite_enabled]": {"false"}, "assignment[turnitin_settings][exclude_quoted]": {"true"}},
+		{"assignment[name]": {`Essay "1", draft`}},
+	}
+	for run := 0; run < 2; run++ {
+		// a resumed run appends to the same log
+		log, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := log.Record("1", "5", prior[run]); err != nil {
+			t.Fatal(err)
+		}
+		log.Close()
+	}
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.CourseID != "1" || entry.AssignmentID != "5" || entry.Time == "" {
+			t.Errorf("entry %d = %+v", i, entry)
+		}
+		if got := entry.Values(); !reflect.DeepEqual(got, prior[i]) {
+			t.Errorf("entry %d Values() = %v, want %v", i, got, prior[i])
+		}
+	}
+}
+
+func TestReadSkipsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollback.jsonl")
+	contents := `{"time":"t1","courseId":"1","assignmentId":"5","params":{"assignment[name]":"Essay"}}
+
+not json
+{"time":"t2","courseId":"1","assignmentId":"6","params":{"assignment[name]":"Lab"}}
+{"time":"t3","courseId":"1","assignmen`
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Read(path)
+	if err == nil || !strings.Contains(err.Error(), "lines 3, 5 of") {
+		t.Errorf("Read error = %v, want lines 3 and 5 reported", err)
+	}
+	if len(entries) != 2 || entries[0].AssignmentID != "5" || entries[1].AssignmentID != "6" {
+		t.Errorf("entries = %+v, want assignments 5 and 6", entries)
+	}
+}
@@ -0,0 +1,105 @@
+// Package safepath turns names that come from Canvas, such as the file names
+// students give their uploads, into file names that are safe to create on
+// any operating system and can not escape the export folder.
+package safepath
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxNameLength is the longest name Name returns, in bytes. File systems
+// allow 255; the rest is left for temporary file suffixes.
+const MaxNameLength = 200
+
+// reserved are the device names Windows refuses as file names, with or
+// without an extension
+var reserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Name returns name as a single safe path element: Unicode NFC normalized,
+// with path separators, control characters and the characters Windows
+// forbids replaced by "_", without leading or trailing dots and spaces, not
+// a reserved device name and at most MaxNameLength bytes with its extension
+// kept. An empty result becomes "_".
+func Name(name string) string {
+	name = norm.NFC.String(name)
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError, unicode.IsControl(r):
+			return '_'
+		case strings.ContainsRune(`/\<>:"|?*`, r):
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, ". ")
+	if base := strings.ToUpper(strings.TrimSpace(strings.SplitN(name, ".", 2)[0])); reserved[base] {
+		name = "_" + name
+	}
+	name = truncate(name, MaxNameLength)
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// truncate shortens name to at most max bytes on a rune boundary, keeping a
+// short extension
+func truncate(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	ext := filepath.Ext(name)
+	if len(ext) > 16 || len(ext) >= max {
+		ext = ""
+	}
+	stem := name[:len(name)-len(ext)]
+	cut := max - len(ext)
+	for cut > 0 && !utf8.RuneStart(stem[cut]) {
+		cut--
+	}
+	return strings.TrimRight(stem[:cut], ". ") + ext
+}
+
+// Join joins root and the elements, each made safe with Name, and checks
+// that the result stays inside root.
+func Join(root string, elem ...string) (string, error) {
+	parts := []string{root}
+	for _, e := range elem {
+		parts = append(parts, Name(e))
+	}
+	path := filepath.Join(parts...)
+	if err := Inside(root, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Inside returns an error unless path is root or lies below it.
+func Inside(root, path string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil {
+		return err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return errors.New("safepath: " + path + " is outside " + root)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package safepath
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"essay.docx", "essay.docx"},
+		{"My Essay (final).docx", "My Essay (final).docx"},
+
+		// traversal and separators
+		{"../../etc/passwd", "_.._etc_passwd"},
+		{`..\..\windows\win.ini`, "_.._windows_win.ini"},
+		{"../", "_"},
+		{"..", "_"},
+		{".", "_"},
+		{"", "_"},
+		{"/etc/passwd", "_etc_passwd"},
+		{`C:\Users\x.txt`, "C__Users_x.txt"},
+
+		// characters Windows forbids and control characters
+		{`a<b>c:d"e|f?g*h.txt`, "a_b_c_d_e_f_g_h.txt"},
+		{"tab\there\x00.txt", "tab_here_.txt"},
+		{"bad\xffbyte.txt", "bad_byte.txt"},
+
+		// leading and trailing dots and spaces
+		{"  .hidden", "hidden"},
+		{"notes.txt. . ", "notes.txt"},
+
+		// reserved device names, with or without an extension
+		{"con.txt", "_con.txt"},
+		{"CON", "_CON"},
+		{"nul .txt", "_nul .txt"},
+		{"aux.tar.gz", "_aux.tar.gz"},
+		{"Com1", "_Com1"},
+		{"lpt9.pdf", "_lpt9.pdf"},
+		{"console.txt", "console.txt"},
+		{"com10.txt", "com10.txt"},
+		{"my con.txt", "my con.txt"},
+
+		// NFC normalization: e followed by a combining acute accent
+		{"re\u0301sume\u0301.pdf", "r\u00e9sum\u00e9.pdf"},
+		{"r\u00e9sum\u00e9.pdf", "r\u00e9sum\u00e9.pdf"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.name); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameTruncate(t *testing.T) {
+	tests := []struct {
+		desc string
+		name string
+		ext  string
+	}{
+		{"ASCII", strings.Repeat("a", 300) + ".docx", ".docx"},
+		{"two-byte runes ending mid-rune", strings.Repeat("\u00e9", 150) + ".docx", ".docx"},
+		{"three-byte runes", strings.Repeat("\u6587", 100) + ".pdf", ".pdf"},
+		{"four-byte runes", strings.Repeat("\U0001F600", 60) + ".txt", ".txt"},
+		{"long extension dropped", strings.Repeat("a", 250) + "." + strings.Repeat("x", 20), ""},
+		{"no extension", strings.Repeat("\u00e9", 150), ""},
+	}
+	for _, tt := range tests {
+		got := Name(tt.name)
+		if len(got) > MaxNameLength {
+			t.Errorf("%s: Name is %d bytes, want at most %d", tt.desc, len(got), MaxNameLength)
+		}
+		if len(got) < MaxNameLength-4 {
+			t.Errorf("%s: Name is %d bytes, cut more than a rune short of %d", tt.desc, len(got), MaxNameLength)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: Name %q is not valid UTF-8", tt.desc, got)
+		}
+		if tt.ext != "" && !strings.HasSuffix(got, tt.ext) {
+			t.Errorf("%s: Name %q lost its extension %s", tt.desc, got, tt.ext)
+		}
+		if tt.ext == "" && strings.Contains(got, ".") {
+			t.Errorf("%s: Name %q kept an extension", tt.desc, got)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		elem []string
+		want string
+	}{
+		{[]string{"1234", "5678", "essay.docx"}, filepath.Join("out", "1234", "5678", "essay.docx")},
+		{[]string{"..", "x"}, filepath.Join("out", "_", "x")},
+		{[]string{"a/../../b"}, filepath.Join("out", "a_.._.._b")},
+		{[]string{`..\..\b`}, filepath.Join("out", "_.._b")},
+	}
+	for _, tt := range tests {
+		got, err := Join("out", tt.elem...)
+		if err != nil {
+			t.Errorf("Join(out, %q): %v", tt.elem, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Join(out, %q) = %q, want %q", tt.elem, got, tt.want)
+		}
+	}
+}
+
+func TestInside(t *testing.T) {
+	tests := []struct {
+		root string
+		path string
+		ok   bool
+	}{
+		{"out", "out", true},
+		{"out", "out/a/b.txt", true},
+		{"out", "out/a/../b.txt", true},
+		{"out", "out/..foo", true},
+		{"out/", "out/a", true},
+		{"out", "out/../x", false},
+		{"out", "out/a/../../x", false},
+		{"out", "x", false},
+		{"out", "..", false},
+		{"out", "/etc/passwd", false},
+		{"out", "out2/x", false},
+		{"out", "out-old", false},
+		{"/data/out", "/data/outside/x", false},
+		{"/data/out", "/data/out/x", true},
+	}
+	for _, tt := range tests {
+		err := Inside(tt.root, filepath.FromSlash(tt.path))
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("Inside(%q, %q) = %v, want ok %v", tt.root, tt.path, err, tt.ok)
+		}
+	}
+}